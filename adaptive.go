@@ -0,0 +1,147 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveLogger wraps a Logger to protect the system from log floods by
+// temporarily raising the priority floor when the volume of emitted
+// messages is too high, and lowering it again once the volume subsides.
+// Unlike RateLimitLogger it drops whole priorities rather than individual
+// messages, so the messages that do get through remain complete.
+//
+// Volume is measured in one-second windows.  As soon as the number of
+// messages emitted in the current window exceeds the limit, the floor is
+// raised by one priority, e.g. from Debug to Info, and a Notice is logged.
+// The floor is never raised above Crit.  At the end of each window the
+// floor is lowered by one priority if the number of messages submitted in
+// that window that would have been emitted at the lower floor is less than
+// half the limit.  The gap between the limit that raises the floor and the
+// lower threshold that relaxes it provides hysteresis, so a volume that
+// hovers around the limit does not cause the floor to flap.  Relaxation is
+// also evaluated only when a message is submitted, so the floor remains
+// raised while the logger is idle.
+//
+// All methods are safe for concurrent use if the wrapped logger is.
+type AdaptiveLogger struct {
+	lgr    Logger
+	max    int
+	window time.Duration
+	mu     sync.Mutex
+	floor  Priority
+	start  time.Time
+	counts [Debug + 1]int
+}
+
+// MakeAdaptiveLogger returns an AdaptiveLogger that forwards to lgr and
+// raises the floor when more than maxPerSec messages are emitted in a
+// second.  Values of maxPerSec less than 1 are replaced by 1.
+func MakeAdaptiveLogger(lgr Logger, maxPerSec int) *AdaptiveLogger {
+	if maxPerSec < 1 {
+		maxPerSec = 1
+	}
+	return &AdaptiveLogger{
+		lgr:    lgr,
+		max:    maxPerSec,
+		window: time.Second,
+	}
+}
+
+// Floor returns the floor currently imposed by the logger, or an unset
+// priority if the logger is not overloaded.
+func (v *AdaptiveLogger) Floor() Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.floor
+}
+
+// effective returns the priority in effect given the floor.  The caller
+// must hold v.mu.
+func (v *AdaptiveLogger) effective(pri Priority) Priority {
+	if v.floor.IsSet() && v.floor < pri {
+		return v.floor
+	}
+	return pri
+}
+
+// admitted returns the number of messages counted in the current window
+// that would be emitted at pri.  The caller must hold v.mu.
+func (v *AdaptiveLogger) admitted(pri Priority) int {
+	n := 0
+	for p := Emerg; p <= pri && p <= Debug; p++ {
+		n += v.counts[p]
+	}
+	return n
+}
+
+// Priority per ImmutableLogger.  While overloaded this is the floor.
+func (v *AdaptiveLogger) Priority() Priority {
+	pri := v.lgr.Priority()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.effective(pri)
+}
+
+// F per ImmutableLogger.
+func (v *AdaptiveLogger) F(pri Priority, format string, args ...interface{}) {
+	base := v.lgr.Priority()
+	if !base.Enables(pri) || pri < Emerg || pri > Debug {
+		return
+	}
+	now := time.Now()
+	var notice string
+	var nargs []interface{}
+
+	v.mu.Lock()
+	if now.Sub(v.start) >= v.window {
+		if v.floor.IsSet() && v.admitted(v.floor+1) < v.max/2 {
+			v.floor++
+			if v.floor >= base {
+				v.floor = unsetPriority
+			}
+			notice = "adaptive floor lowered to %s"
+			nargs = []interface{}{v.effective(base)}
+		}
+		v.start = now
+		v.counts = [Debug + 1]int{}
+	}
+	v.counts[pri]++
+	eff := v.effective(base)
+	if n := v.admitted(eff); n > v.max && eff > Crit {
+		v.floor = eff - 1
+		notice = "adaptive floor raised to %s: %d messages exceed %d/s"
+		nargs = []interface{}{v.floor, n, v.max}
+	}
+	enabled := v.effective(base).Enables(pri)
+	v.mu.Unlock()
+
+	if notice != "" {
+		v.lgr.F(Notice, notice, nargs...)
+	}
+	if enabled {
+		v.lgr.F(pri, format, args...)
+	}
+}
+
+// Audit per Auditor.  Audit messages are not subject to the adaptive
+// floor.
+func (v *AdaptiveLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *AdaptiveLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.  This changes the underlying logger's priority
+// and does not affect any floor in effect.
+func (v *AdaptiveLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
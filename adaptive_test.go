@@ -0,0 +1,52 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeAdaptiveLogger(newTestLogger(&sb), 4)
+	lgr.window = 50 * time.Millisecond
+
+	for i := 0; i < 6; i++ {
+		lgr.F(Debug, "d%d", i)
+	}
+	lgr.F(Info, "kept")
+	if p := lgr.Floor(); p != Info {
+		t.Errorf("wrong floor: %s", p)
+	}
+	if p := lgr.Priority(); p != Info {
+		t.Errorf("wrong priority: %s", p)
+	}
+	exp := "[D] d0\n[D] d1\n[D] d2\n[D] d3\n" +
+		"[N] adaptive floor raised to Info: 5 messages exceed 4/s\n" +
+		"[I] kept\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong flood output: %q", s)
+	}
+	sb.Reset()
+
+	// The flood in the previous window keeps the floor raised.
+	time.Sleep(lgr.window)
+	lgr.F(Debug, "dropped")
+	if p := lgr.Floor(); p != Info {
+		t.Errorf("floor relaxed early: %s", p)
+	}
+
+	// A quiet window relaxes it.
+	time.Sleep(lgr.window)
+	lgr.F(Debug, "resumed")
+	if p := lgr.Floor(); p.IsSet() {
+		t.Errorf("floor not relaxed: %s", p)
+	}
+	exp = "[N] adaptive floor lowered to Debug\n[D] resumed\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong relaxed output: %q", s)
+	}
+}
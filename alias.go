@@ -0,0 +1,124 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidAliases indicates that MakePriorityAliases was given aliases
+// that cannot be used together.
+var ErrInvalidAliases = errors.New("invalid priority aliases")
+
+// PriorityAlias is an alternative name for a priority.
+type PriorityAlias struct {
+	// Name is the alias, e.g. "fatal" or "trace".  Names are matched
+	// without regard to case.
+	Name string
+	// Priority is the built-in priority the alias stands for.
+	Priority Priority
+}
+
+// PriorityAliases maps the severity names of an external taxonomy onto the
+// built-in priorities, so configuration written for an organization's
+// existing levels, such as "fatal" or "trace", can be used to select
+// priorities.
+//
+// Aliases only add names.  Ordering and filtering are those of the built-in
+// priorities the aliases stand for, so aliases for the same priority, e.g.
+// "trace" and "debug" both for Debug, cannot be filtered separately.
+type PriorityAliases struct {
+	aliases []PriorityAlias
+	byName  map[string]Priority
+}
+
+// MakePriorityAliases creates a set of aliases listed in order of
+// decreasing severity.  Each alias must have a non-empty name that is
+// unique without regard to case, and a valid priority that is not more
+// severe than the priority of the preceding alias.
+func MakePriorityAliases(aliases ...PriorityAlias) (*PriorityAliases, error) {
+	if len(aliases) == 0 {
+		return nil, fmt.Errorf("%w: no aliases", ErrInvalidAliases)
+	}
+	pa := &PriorityAliases{
+		aliases: append([]PriorityAlias(nil), aliases...),
+		byName:  make(map[string]Priority, len(aliases)),
+	}
+	prev := Emerg
+	for _, a := range aliases {
+		name := strings.ToLower(a.Name)
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("%w: empty name", ErrInvalidAliases)
+		case a.Priority < Emerg || a.Priority > Debug:
+			return nil, fmt.Errorf("%w: %s: bad priority %d", ErrInvalidAliases, a.Name, a.Priority)
+		case a.Priority < prev:
+			return nil, fmt.Errorf("%w: %s: out of order", ErrInvalidAliases, a.Name)
+		}
+		if _, dup := pa.byName[name]; dup {
+			return nil, fmt.Errorf("%w: %s: duplicate name", ErrInvalidAliases, a.Name)
+		}
+		pa.byName[name] = a.Priority
+		prev = a.Priority
+	}
+	return pa, nil
+}
+
+// Aliases returns a copy of the aliases.
+func (pa *PriorityAliases) Aliases() []PriorityAlias {
+	return append([]PriorityAlias(nil), pa.aliases...)
+}
+
+// Parse returns the priority for the alias name, without regard to case.
+// If there is no such alias the name is parsed as a built-in priority
+// identifier.  The boolean is false if name is not recognized.
+func (pa *PriorityAliases) Parse(name string) (Priority, bool) {
+	if pri, ok := pa.byName[strings.ToLower(name)]; ok {
+		return pri, true
+	}
+	return parseBuiltinPriority(name)
+}
+
+// Name returns the first alias for pri, or the built-in name of pri if it
+// has no alias.
+func (pa *PriorityAliases) Name(pri Priority) string {
+	for _, a := range pa.aliases {
+		if a.Priority == pri {
+			return a.Name
+		}
+	}
+	return pri.String()
+}
+
+var (
+	aliasMu       sync.RWMutex
+	activeAliases *PriorityAliases
+)
+
+// SetPriorityAliases makes pa the aliases accepted by ParsePriority, and so
+// by Priority.Set and Priority.UnmarshalText, for the whole process.
+// Passing nil removes them, which is the default.
+//
+// Only parsing is affected.  Priority.String and Priority.MarshalText
+// continue to produce built-in names, and loggers render built-in labels;
+// use PriorityAliases.Name to display a priority by its alias.  Built-in
+// names remain acceptable input, so marshalled priorities can always be
+// read back.  Libraries should not invoke this; the aliases belong to the
+// application.
+func SetPriorityAliases(pa *PriorityAliases) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	activeAliases = pa
+}
+
+// ActivePriorityAliases returns the aliases installed by
+// SetPriorityAliases, or nil if none are.
+func ActivePriorityAliases() *PriorityAliases {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	return activeAliases
+}
@@ -0,0 +1,80 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPriorityAliases(t *testing.T) {
+	bad := [][]PriorityAlias{
+		nil,
+		{{"", Error}},
+		{{"x", unsetPriority}},
+		{{"x", Debug + 1}},
+		{{"x", Error}, {"y", Crit}},
+		{{"x", Error}, {"X", Warning}},
+	}
+	for i, aliases := range bad {
+		if _, err := MakePriorityAliases(aliases...); !errors.Is(err, ErrInvalidAliases) {
+			t.Errorf("%d: bad aliases accepted: %v", i, err)
+		}
+	}
+
+	aliases := []PriorityAlias{
+		{"Fatal", Emerg},
+		{"Error", Error},
+		{"Warn", Warning},
+		{"Info", Info},
+		{"Debug", Debug},
+		{"Trace", Debug},
+	}
+	pa, err := MakePriorityAliases(aliases...)
+	if err != nil {
+		t.Fatalf("make: %v", err)
+	}
+	aliases[0].Name = "changed"
+	if l := pa.Aliases(); len(l) != 6 || l[0].Name != "Fatal" {
+		t.Errorf("wrong aliases: %v", l)
+	}
+	if n := pa.Name(Debug); n != "Debug" {
+		t.Errorf("wrong debug name: %s", n)
+	}
+	if n := pa.Name(Notice); n != "Notice" {
+		t.Errorf("wrong fallback name: %s", n)
+	}
+
+	if _, ok := ParsePriority("trace"); ok {
+		t.Errorf("aliases active by default")
+	}
+	SetPriorityAliases(pa)
+	defer SetPriorityAliases(nil)
+	if ActivePriorityAliases() != pa {
+		t.Errorf("aliases not active")
+	}
+	tests := []struct {
+		s   string
+		pri Priority
+	}{
+		{"FATAL", Emerg},
+		{"trace", Debug},
+		{"notice", Notice},
+		{"crit", Crit},
+	}
+	for _, tc := range tests {
+		var pri Priority
+		if err := pri.Set(tc.s); err != nil || pri != tc.pri {
+			t.Errorf("%s: got %s %v", tc.s, pri, err)
+		}
+	}
+	if _, ok := ParsePriority("verbose"); ok {
+		t.Errorf("unknown name accepted")
+	}
+
+	SetPriorityAliases(nil)
+	if _, ok := ParsePriority("trace"); ok {
+		t.Errorf("aliases not removed")
+	}
+}
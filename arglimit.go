@@ -0,0 +1,99 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ArgTruncated follows the retained part of an argument that was cut to
+// satisfy the limit set with LogLogger.SetArgLimit.
+const ArgTruncated = "...[truncated]"
+
+// limitArgs returns a copy of args in which each argument is wrapped so its
+// rendering is cut to at most max bytes.  Arguments consumed by a '*' width
+// or precision or by the %T verb are not wrapped, since fmt handles those
+// without consulting fmt.Formatter.  If format uses explicit argument
+// indexes the verbs are not tracked, and all arguments except those of type
+// int are wrapped.
+func limitArgs(format string, args []interface{}, max int) []interface{} {
+	rv := make([]interface{}, len(args))
+	copy(rv, args)
+	wrap := make([]bool, len(args))
+	ai := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				for j, a := range args {
+					_, isInt := a.(int)
+					wrap[j] = !isInt
+				}
+				ai = len(args)
+				i = len(format)
+				break
+			}
+			if c == '*' {
+				ai++
+				continue
+			}
+			if strings.IndexByte("+-# 0.123456789", c) >= 0 {
+				continue
+			}
+			if c != '%' {
+				if ai < len(wrap) {
+					wrap[ai] = c != 'T'
+				}
+				ai++
+			}
+			break
+		}
+	}
+	for i, w := range wrap {
+		if w {
+			rv[i] = limitedArg{
+				arg: args[i],
+				max: max,
+			}
+		}
+	}
+	return rv
+}
+
+// limitedArg wraps a message argument so that its rendered form is cut to
+// at most max bytes.
+type limitedArg struct {
+	arg interface{}
+	max int
+}
+
+// Format implements fmt.Formatter by rendering the wrapped argument with the
+// same verb, flags, width, and precision, then cutting the result.
+func (a limitedArg) Format(f fmt.State, verb rune) {
+	spec := []byte{'%'}
+	for _, c := range "+-# 0" {
+		if f.Flag(int(c)) {
+			spec = append(spec, byte(c))
+		}
+	}
+	if w, ok := f.Width(); ok {
+		spec = strconv.AppendInt(spec, int64(w), 10)
+	}
+	if p, ok := f.Precision(); ok {
+		spec = append(spec, '.')
+		spec = strconv.AppendInt(spec, int64(p), 10)
+	}
+	spec = append(spec, string(verb)...)
+	s := fmt.Sprintf(string(spec), a.arg)
+	if len(s) > a.max {
+		s = s[:runeCut(s, a.max)] + ArgTruncated
+	}
+	_, _ = io.WriteString(f, s)
+}
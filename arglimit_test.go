@@ -0,0 +1,53 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogLoggerArgLimit(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+	big := strings.Repeat("x", 100)
+
+	lgr.F(Info, "%s", big)
+	if s := sb.String(); s != "[I] "+big+"\n" {
+		t.Errorf("limited by default: %q", s)
+	}
+	sb.Reset()
+
+	ll.SetArgLimit(5)
+	lgr.F(Info, "format is not limited: %s %d %5.1f|%-4d|%T %q", big, 1234567, 3.14159, 7, big, "ab")
+	exp := "[I] format is not limited: xxxxx" + ArgTruncated + " 12345" + ArgTruncated +
+		"   3.1|7   |string \"ab\"\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong limited output:\n got %q\nwant %q", s, exp)
+	}
+	sb.Reset()
+
+	ll.SetArgLimit(4)
+	FF(lgr, Info, Fields{"k": "v"}, "%v", []int{1, 2, 3})
+	lgr.F(Info, "%s", "hééllo")
+	exp = "[I] [1 2" + ArgTruncated + " k=v\n[I] hé" + ArgTruncated + "\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output:\n got %q\nwant %q", s, exp)
+	}
+	sb.Reset()
+
+	lgr.F(Info, "%*d|%.*s|%%", 4, 42, 3, "abcdef")
+	lgr.F(Info, "%[2]s %[1]*[3]d", 4, "abcdef", 7)
+	if s := sb.String(); s != "[I]   42|abc|%\n[I] abcd"+ArgTruncated+"    7\n" {
+		t.Errorf("wrong star output: %q", s)
+	}
+	sb.Reset()
+
+	ll.SetArgLimit(0)
+	lgr.F(Info, "%s", big)
+	if s := sb.String(); s != "[I] "+big+"\n" {
+		t.Errorf("limit not removed: %q", s)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync/atomic"
+)
+
+// MakeAttemptLogf creates a Logf bound to lgr whose priority escalates
+// with each call, for logging the attempts of an operation that is retried:
+// early failures are quiet, and later ones loud.  The first call emits at
+// schedule[0], the second at schedule[1], and so on.  Once the schedule is
+// exhausted every further call uses its last priority, e.g. with
+//
+//	logf := MakeAttemptLogf(lgr, []Priority{Debug, Debug, Warning, Error})
+//
+// the first two attempts are logged at Debug, the third at Warning, and
+// the fourth and all later attempts at Error.  An empty schedule logs every
+// attempt at Warning.  The schedule is copied.
+//
+// Create a new Logf for each operation.  Calls may be made concurrently;
+// each call is assigned the next position in the schedule.
+func MakeAttemptLogf(lgr ImmutableLogger, schedule []Priority) Logf {
+	if len(schedule) == 0 {
+		schedule = []Priority{Warning}
+	} else {
+		schedule = append([]Priority(nil), schedule...)
+	}
+	var calls uint64
+	return func(format string, args ...interface{}) {
+		n := atomic.AddUint64(&calls, 1) - 1
+		if n >= uint64(len(schedule)) {
+			n = uint64(len(schedule) - 1)
+		}
+		lgr.F(schedule[n], format, args...)
+	}
+}
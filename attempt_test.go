@@ -0,0 +1,34 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeAttemptLogf(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	schedule := []Priority{Debug, Debug, Warning, Error}
+	logf := MakeAttemptLogf(lgr, schedule)
+	schedule[0] = Emerg
+	for i := 1; i <= 6; i++ {
+		logf("attempt %d failed", i)
+	}
+	exp := "[D] attempt 1 failed\n[D] attempt 2 failed\n[W] attempt 3 failed\n" +
+		"[E] attempt 4 failed\n[E] attempt 5 failed\n[E] attempt 6 failed\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	sb.Reset()
+
+	logf = MakeAttemptLogf(lgr, nil)
+	logf("a")
+	logf("b")
+	if s := sb.String(); s != "[W] a\n[W] b\n" {
+		t.Errorf("wrong empty schedule output: %q", s)
+	}
+}
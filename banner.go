@@ -0,0 +1,22 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+// BannerLogMaker returns a LogMaker that creates loggers with mk and emits
+// banner through each at pri before returning it, so the output of every
+// logger starts with identifying context such as a version and summary of
+// configuration.  The banner is emitted exactly once per logger, before
+// any message from the code that requested the logger.
+//
+// The banner is subject to the priority of the new logger, which for the
+// LogMakers provided by this package is Warning, so a banner at a lower
+// priority is emitted only if mk configures a more verbose priority.  The
+// banner text is emitted verbatim, not used as a format.
+func BannerLogMaker(mk LogMaker, pri Priority, banner string) LogMaker {
+	return func(owner interface{}) Logger {
+		lgr := mk(owner)
+		lgr.F(pri, "%s", banner)
+		return lgr
+	}
+}
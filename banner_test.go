@@ -0,0 +1,34 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBannerLogMaker(t *testing.T) {
+	var sb strings.Builder
+	base := func(interface{}) Logger {
+		return newTestLogger(&sb)
+	}
+
+	mk := BannerLogMaker(base, Notice, "tool v1.2 %d")
+	lgr := mk(nil)
+	lgr.F(Info, "first")
+	mk(nil)
+	if s := sb.String(); s != "[N] tool v1.2 %d\n[I] first\n[N] tool v1.2 %d\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	sb.Reset()
+
+	BannerLogMaker(NullLogMaker, Warning, "dropped")(nil)
+	mk = BannerLogMaker(func(interface{}) Logger {
+		return newTestLogger(&sb).SetPriority(Warning)
+	}, Notice, "filtered")
+	mk(nil)
+	if s := sb.String(); s != "" {
+		t.Errorf("banner not filtered: %q", s)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// blockMessage is a message formatted for a Block.
+type blockMessage struct {
+	t   time.Time
+	pri Priority
+	s   string
+}
+
+// blockEmitter is implemented by loggers that can emit the messages of a
+// Block as a unit.
+type blockEmitter interface {
+	// emitBlock emits msgs as a unit.  calldepth counts the frames
+	// between emitBlock and the code that should be identified as the
+	// source of the messages.
+	emitBlock(calldepth int, msgs []blockMessage)
+}
+
+// emitBlock emits msgs through lgr, as a unit if lgr supports it.
+func emitBlock(lgr ImmutableLogger, calldepth int, msgs []blockMessage) {
+	if be, ok := lgr.(blockEmitter); ok {
+		be.emitBlock(calldepth+1, msgs)
+		return
+	}
+	for _, m := range msgs {
+		lgr.F(m.pri, "%s", m.s)
+	}
+}
+
+// Block collects related messages so they can be emitted together, keeping
+// multi-line output of an operation contiguous when other goroutines are
+// logging concurrently.  A Block is an ImmutableLogger, so it can be passed
+// to code that produces the related messages.
+//
+// Messages passed to F are formatted immediately, if the underlying logger
+// would emit them, and buffered until Close is invoked.  Close submits the
+// buffered messages to the underlying logger as a unit: a LogLogger emits
+// them with a single write, and a channel logger sends them to its consumer
+// as a single Emitter.  Other loggers receive the messages through
+// individual F calls, which may be interleaved with messages from other
+// goroutines.  Messages submitted after Close are not buffered but are
+// passed directly to the underlying logger.
+//
+// All methods are safe for concurrent use.
+type Block struct {
+	lgr    ImmutableLogger
+	mu     sync.Mutex
+	msgs   []blockMessage
+	closed bool
+}
+
+// StartBlock returns a Block that buffers messages for lgr.
+func StartBlock(lgr ImmutableLogger) *Block {
+	return &Block{
+		lgr: lgr,
+	}
+}
+
+// Priority per ImmutableLogger.
+func (b *Block) Priority() Priority {
+	return b.lgr.Priority()
+}
+
+// F per ImmutableLogger.  The message is formatted and the time it is
+// emitted with is recorded when F is invoked.
+func (b *Block) F(pri Priority, format string, args ...interface{}) {
+	if !b.lgr.Priority().Enables(pri) {
+		return
+	}
+	m := blockMessage{
+		t:   time.Now(),
+		pri: pri,
+		s:   fmt.Sprintf(format, args...),
+	}
+	b.mu.Lock()
+	closed := b.closed
+	if !closed {
+		b.msgs = append(b.msgs, m)
+	}
+	b.mu.Unlock()
+	if closed {
+		b.lgr.F(pri, "%s", m.s)
+	}
+}
+
+// Close emits the buffered messages as a unit.  Subsequent invocations
+// have no effect.
+func (b *Block) Close() {
+	b.mu.Lock()
+	msgs := b.msgs
+	b.msgs = nil
+	b.closed = true
+	b.mu.Unlock()
+	if len(msgs) != 0 {
+		emitBlock(b.lgr, 2, msgs)
+	}
+}
+
+// emitBlock per blockEmitter.  Each message is rendered with the time it
+// was submitted to the Block, and all are written with one Write call.
+func (v *LogLogger) emitBlock(calldepth int, msgs []blockMessage) {
+	var err error
+	v.mu.Lock()
+	var buf []byte
+	if v.progress {
+		buf = append(buf, '\n')
+		v.progress = false
+	}
+	pri := v.priority()
+	for _, m := range msgs {
+		if pri.Enables(m.pri) {
+			buf = v.render(buf, m.t, calldepth+1, m.pri, m.s)
+			if len(m.s) == 0 || m.s[len(m.s)-1] != '\n' {
+				buf = append(buf, '\n')
+			}
+		}
+	}
+	if len(buf) != 0 {
+		_, err = v.lgr.Writer().Write(buf)
+	}
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// emitBlock per blockEmitter.  The messages are sent as a single Emitter
+// and emitted as a unit by the underlying logger when it is emitted.
+func (v *chanLogger) emitBlock(calldepth int, msgs []blockMessage) {
+	if v == nil {
+		return
+	}
+	if v.pfx != "" {
+		pm := make([]blockMessage, len(msgs))
+		for i, m := range msgs {
+			m.s = v.pfx + m.s
+			pm[i] = m
+		}
+		msgs = pm
+	}
+	v.send(&emittable{
+		lgr:   v.lgr,
+		pfx:   v.pfx,
+		block: msgs,
+	})
+}
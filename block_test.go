@@ -0,0 +1,91 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeRecorder records each Write call.
+type writeRecorder struct {
+	writes []string
+}
+
+func (w *writeRecorder) Write(data []byte) (int, error) {
+	w.writes = append(w.writes, string(data))
+	return len(data), nil
+}
+
+func TestBlockLogLogger(t *testing.T) {
+	w := &writeRecorder{}
+	lgr := LogLogMaker(nil).SetPriority(Info)
+	lgr.(*LogLogger).SetOutput(w).Instance().SetFlags(log.Lshortfile)
+
+	b := StartBlock(lgr)
+	b.F(Info, "one %d", 1)
+	b.F(Debug, "filtered")
+	lgr.F(Warning, "outside")
+	b.F(Error, "two")
+	src := filepath.Base(hereKey(1)) + ": "
+	b.Close()
+	b.Close()
+	b.F(Notice, "after")
+
+	exp := []string{
+		"[W] outside\n",
+		src + "[I] one 1\n" + src + "[E] two\n",
+		"[N] after\n",
+	}
+	if len(w.writes) != len(exp) {
+		t.Fatalf("wrong writes: %q", w.writes)
+	}
+	for i, s := range exp {
+		if !strings.HasSuffix(w.writes[i], s) {
+			t.Errorf("write %d wrong: %q", i, w.writes[i])
+		}
+	}
+}
+
+func TestBlockChanLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr, lch := MakeChanLogger(newTestLogger(&sb), 4)
+	plgr := PrefixedChanLogger(lgr, "p: ")
+
+	b := StartBlock(plgr)
+	b.F(Info, "one")
+	plgr.F(Info, "outside")
+	b.F(Info, "two")
+	b.Close()
+	StartBlock(plgr).Close()
+	if n := len(lch); n != 2 {
+		t.Fatalf("wrong pending: %d", n)
+	}
+	(<-lch).Emit()
+	e := <-lch
+	if p := EmitterPrefix(e); p != "p: " {
+		t.Errorf("wrong prefix: %q", p)
+	}
+	e.Emit()
+	if s := sb.String(); s != "[I] p: outside\n[I] p: one\n[I] p: two\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestBlockOther(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeMaxLineLogger(newTestLogger(&sb), 20)
+	b := StartBlock(lgr)
+	b.F(Info, "one")
+	b.F(Warning, "two")
+	if s := sb.String(); s != "" {
+		t.Errorf("not buffered: %q", s)
+	}
+	b.Close()
+	if s := sb.String(); s != "[I] one\n[W] two\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
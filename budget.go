@@ -0,0 +1,138 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BudgetSampleStart is the fraction of the daily budget that BudgetLogger
+// allows to be used before it starts to drop messages.
+const BudgetSampleStart = 0.5
+
+// BudgetLogger wraps a Logger to keep the volume of messages under a daily
+// byte budget, for cost control when the output goes to a metered log
+// service.  The size of a message is the length of its formatted text;
+// identifiers, timestamps, and other additions made by the wrapped logger
+// are not counted, so the budget should leave room for them.
+//
+// Until BudgetSampleStart of the budget has been used every message is
+// emitted.  Beyond that messages are dropped at random with a probability
+// that rises linearly from zero to one as usage approaches the budget, and
+// once the budget is exhausted all of them are dropped.  Messages at Error
+// and more severe priorities are exempt: they are always emitted, and their
+// size counts against the budget.  Usage resets to zero when the UTC date
+// changes.
+//
+// All methods are safe for concurrent use if the wrapped logger is.
+type BudgetLogger struct {
+	lgr    Logger
+	budget int64
+	mu     sync.Mutex
+	used   int64
+	day    int
+	rnd    func() float64
+}
+
+// MakeBudgetLogger returns a BudgetLogger that forwards to lgr subject to
+// a budget of dailyBytes bytes per UTC day.  Values of dailyBytes less than
+// 1 are replaced by 1.
+func MakeBudgetLogger(lgr Logger, dailyBytes int64) *BudgetLogger {
+	if dailyBytes < 1 {
+		dailyBytes = 1
+	}
+	return &BudgetLogger{
+		lgr:    lgr,
+		budget: dailyBytes,
+		day:    budgetDay(time.Now()),
+		rnd:    rand.Float64,
+	}
+}
+
+// budgetDay identifies the UTC day containing t.
+func budgetDay(t time.Time) int {
+	y, m, d := t.UTC().Date()
+	return (y*100+int(m))*100 + d
+}
+
+// rollover resets usage if the day has changed.  The caller must hold
+// v.mu.
+func (v *BudgetLogger) rollover(now time.Time) {
+	if day := budgetDay(now); day != v.day {
+		v.day = day
+		v.used = 0
+	}
+}
+
+// Remaining returns the number of bytes left in today's budget.  It is
+// zero once the budget is exhausted, even if exempt messages have exceeded
+// it.
+func (v *BudgetLogger) Remaining() int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rollover(time.Now())
+	if v.used >= v.budget {
+		return 0
+	}
+	return v.budget - v.used
+}
+
+// admit determines whether a message of n bytes at pri is emitted, and if
+// so charges it to the budget.
+func (v *BudgetLogger) admit(pri Priority, n int) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rollover(time.Now())
+	if pri > Error {
+		frac := float64(v.used) / float64(v.budget)
+		if frac >= 1 {
+			return false
+		}
+		if frac > BudgetSampleStart {
+			pdrop := (frac - BudgetSampleStart) / (1 - BudgetSampleStart)
+			if v.rnd() < pdrop {
+				return false
+			}
+		}
+	}
+	v.used += int64(n)
+	return true
+}
+
+// Priority per ImmutableLogger.
+func (v *BudgetLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *BudgetLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	s := fmt.Sprintf(format, args...)
+	if v.admit(pri, len(s)) {
+		v.lgr.F(pri, "%s", s)
+	}
+}
+
+// Audit per Auditor.  Audit messages are not charged to the budget and
+// are never dropped.
+func (v *BudgetLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *BudgetLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *BudgetLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
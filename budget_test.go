@@ -0,0 +1,61 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudgetLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeBudgetLogger(newTestLogger(&sb), 100)
+	draw := 0.0
+	lgr.rnd = func() float64 {
+		return draw
+	}
+	msg := strings.Repeat("x", 10)
+
+	// Below half the budget nothing is dropped.
+	draw = 0
+	for i := 0; i < 5; i++ {
+		lgr.F(Info, "%s", msg)
+	}
+	if r := lgr.Remaining(); r != 50 {
+		t.Errorf("wrong remaining: %d", r)
+	}
+	// At 60% usage the drop probability is 20%.
+	lgr.F(Info, "%s", msg)
+	draw = 0.1
+	lgr.F(Info, "dropped")
+	draw = 0.3
+	lgr.F(Info, "%s", msg)
+	if r := lgr.Remaining(); r != 30 {
+		t.Errorf("wrong sampled remaining: %d", r)
+	}
+	if n := strings.Count(sb.String(), "\n"); n != 7 {
+		t.Errorf("wrong emitted count: %d", n)
+	}
+	sb.Reset()
+
+	// Errors are exempt and charged.
+	draw = 0
+	for i := 0; i < 4; i++ {
+		lgr.F(Error, "%s", msg)
+	}
+	if r := lgr.Remaining(); r != 0 {
+		t.Errorf("wrong exhausted remaining: %d", r)
+	}
+	lgr.F(Warning, "dropped")
+	lgr.F(Crit, "crit")
+	if n := strings.Count(sb.String(), "\n"); n != 5 || strings.Contains(sb.String(), "dropped") {
+		t.Errorf("wrong exhausted output: %q", sb.String())
+	}
+
+	// A new day resets usage.
+	lgr.day--
+	if r := lgr.Remaining(); r != 100 {
+		t.Errorf("not reset: %d", r)
+	}
+}
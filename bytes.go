@@ -0,0 +1,52 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// BytesEncoding selects how FBytesAs renders binary data.
+type BytesEncoding int
+
+const (
+	// BytesHex renders data as lower-case hexadecimal digits.
+	BytesHex BytesEncoding = iota
+	// BytesBase64 renders data in standard base64 with padding.
+	BytesBase64
+)
+
+// String returns the name of the encoding.
+func (e BytesEncoding) String() string {
+	if e == BytesBase64 {
+		return "base64"
+	}
+	return "hex"
+}
+
+// encode returns data rendered in the encoding.
+func (e BytesEncoding) encode(data []byte) string {
+	if e == BytesBase64 {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return hex.EncodeToString(data)
+}
+
+// FBytes emits through lgr a message displaying short binary data in
+// hexadecimal, as with FBytesAs using BytesHex.
+func FBytes(lgr ImmutableLogger, pri Priority, label string, data []byte) {
+	FBytesAs(lgr, pri, BytesHex, label, data)
+}
+
+// FBytesAs emits through lgr a message displaying binary data in a text
+// encoding that cannot corrupt the log stream, e.g. "proto [4 hex]: 00ff1a2b"
+// for label "proto".  This captures values like protocol identifiers that
+// may contain null or control bytes, which would be mangled by "%s".  The
+// data is encoded only if lgr would emit the message.
+func FBytesAs(lgr ImmutableLogger, pri Priority, enc BytesEncoding, label string, data []byte) {
+	if lgr.Priority().Enables(pri) {
+		lgr.F(pri, "%s [%d %s]: %s", label, len(data), enc, enc.encode(data))
+	}
+}
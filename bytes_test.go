@@ -0,0 +1,28 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFBytes(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	data := []byte{0x00, 0xff, 0x1a, '\n'}
+
+	FBytes(lgr, Info, "proto", data)
+	FBytesAs(lgr, Info, BytesBase64, "proto", data)
+	FBytes(lgr, Info, "empty", nil)
+	lgr.SetPriority(Warning)
+	FBytes(lgr, Info, "filtered", data)
+
+	exp := "[I] proto [4 hex]: 00ff1a0a\n" +
+		"[I] proto [4 base64]: AP8aCg==\n" +
+		"[I] empty [0 hex]: \n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// CallSiteLogger wraps a Logger to count the messages it emits by the source
+// location of the call that produced them.  This identifies the statements
+// that contribute most to log volume.
+//
+// Counting is disabled until enabled with SetEnabled, because obtaining the
+// caller of each message is expensive.  Only messages that pass the priority
+// filter are counted.  All methods are safe for concurrent use if the
+// wrapped logger is.
+type CallSiteLogger struct {
+	lgr     Logger
+	mu      sync.Mutex
+	enabled bool
+	skip    int
+	counts  map[string]uint64
+}
+
+// MakeCallSiteLogger returns a CallSiteLogger that forwards to lgr.
+// Counting is initially disabled.
+func MakeCallSiteLogger(lgr Logger) *CallSiteLogger {
+	return &CallSiteLogger{
+		lgr:    lgr,
+		counts: make(map[string]uint64),
+	}
+}
+
+// SetEnabled controls whether emitted messages are counted.
+func (v *CallSiteLogger) SetEnabled(enabled bool) *CallSiteLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.enabled = enabled
+	return v
+}
+
+// SetSkip specifies the number of wrapper frames between the call site and
+// the invocation of F.  For example a Logf obtained from MakePriWrapper or
+// MakePriPr adds one frame.  The default is zero, which attributes messages
+// to the direct caller of F.
+func (v *CallSiteLogger) SetSkip(skip int) *CallSiteLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.skip = skip
+	return v
+}
+
+// Snapshot returns a copy of the accumulated counts, keyed by "file:line"
+// using the full path of the source file.
+func (v *CallSiteLogger) Snapshot() map[string]uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rv := make(map[string]uint64, len(v.counts))
+	for k, n := range v.counts {
+		rv[k] = n
+	}
+	return rv
+}
+
+// Reset discards the accumulated counts.
+func (v *CallSiteLogger) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.counts = make(map[string]uint64)
+}
+
+// Priority per ImmutableLogger.
+func (v *CallSiteLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *CallSiteLogger) F(pri Priority, format string, args ...interface{}) {
+	v.mu.Lock()
+	enabled, skip := v.enabled, v.skip
+	v.mu.Unlock()
+	if enabled && v.lgr.Priority().Enables(pri) {
+		key := "???"
+		if _, file, line, ok := runtime.Caller(1 + skip); ok {
+			key = file + ":" + strconv.Itoa(line)
+		}
+		v.mu.Lock()
+		v.counts[key]++
+		v.mu.Unlock()
+	}
+	v.lgr.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are not counted.
+func (v *CallSiteLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *CallSiteLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *CallSiteLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
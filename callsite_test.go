@@ -0,0 +1,51 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// Return the file:line key for the caller's call site.
+func hereKey(delta int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return file + ":" + strconv.Itoa(line+delta)
+}
+
+func TestCallSiteLogger(t *testing.T) {
+	lgr := MakeCallSiteLogger(NullLogMaker(nil))
+
+	lgr.F(Error, "not counted")
+	if n := len(lgr.Snapshot()); n != 0 {
+		t.Errorf("counted while disabled: %d", n)
+	}
+
+	lgr.SetEnabled(true)
+	for i := 0; i < 3; i++ {
+		lgr.F(Error, "direct")
+		lgr.F(Debug, "filtered")
+	}
+	direct := hereKey(-3)
+
+	lpr := MakePriPr(lgr.SetSkip(1))
+	lpr.E("wrapped")
+	wrapped := hereKey(-1)
+
+	snap := lgr.Snapshot()
+	if len(snap) != 2 || snap[direct] != 3 || snap[wrapped] != 1 {
+		t.Errorf("wrong counts: %v", snap)
+	}
+
+	lgr.Reset()
+	if n := len(lgr.Snapshot()); n != 0 {
+		t.Errorf("not reset: %d", n)
+	}
+
+	var l Logger = lgr
+	if l.SetPriority(Debug).SetId("x").Priority() != Debug {
+		t.Errorf("priority not delegated")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Compile-time checks that the loggers provided by this package implement
+// the interfaces they document.  Implementers of custom loggers are
+// encouraged to use the same pattern, e.g.:
+//
+//	var _ logwrap.Logger = (*MyLogger)(nil)
+//
+// and then to verify the runtime behavior with CheckLogger in a test.
+var (
+	_ Logger = (*LogLogger)(nil)
+	_ Logger = (*SliceLogger)(nil)
+	_ Logger = (*TappedLogger)(nil)
+	_ Logger = (*ECSLogger)(nil)
+	_ Logger = (*AdaptiveLogger)(nil)
+	_ Logger = (*BudgetLogger)(nil)
+	_ Logger = (*CallSiteLogger)(nil)
+	_ Logger = (*ContentPriorityLogger)(nil)
+	_ Logger = (*EmissionLogger)(nil)
+	_ Logger = (*EpochLogger)(nil)
+	_ Logger = (*FailoverLogger)(nil)
+	_ Logger = (*FirehoseLogger)(nil)
+	_ Logger = (*GlobalRateLimitLogger)(nil)
+	_ Logger = (*KeyedOnceLogger)(nil)
+	_ Logger = (*LoggerHandle)(nil)
+	_ Logger = (*MaxLineLogger)(nil)
+	_ Logger = (*OnceLogger)(nil)
+	_ Logger = (*OpLogger)(nil)
+	_ Logger = (*QuietLogger)(nil)
+	_ Logger = (*RateLimitLogger)(nil)
+	_ Logger = (*ReportLogger)(nil)
+	_ Logger = (*RoutingLogger)(nil)
+	_ Logger = (*RunIdLogger)(nil)
+	_ Logger = (*SeverityLogger)(nil)
+
+	_ CheckedLogger = (*LogLogger)(nil)
+	_ CheckedLogger = (*FailoverLogger)(nil)
+	_ TimedLogger   = (*LogLogger)(nil)
+	_ GuardedLogger = (*LogLogger)(nil)
+	_ GuardedLogger = (*EmissionLogger)(nil)
+	_ FieldLogger   = (*LogLogger)(nil)
+	_ IdAppender    = (*LogLogger)(nil)
+)
+
+// ErrLoggerContract indicates that CheckLogger found behavior that does
+// not match the documented contract of Logger.
+var ErrLoggerContract = errors.New("logger contract violation")
+
+// CheckLogger verifies at runtime that lgr behaves as the Logger contract
+// requires, beyond the method signatures that the compiler checks.  It is
+// intended for use in the tests of custom Logger implementations.  lgr
+// must be newly constructed, as CheckLogger verifies:
+//
+//   - the default priority is Warning;
+//   - SetPriority returns a non-nil Logger, and Priority reflects the value
+//     set for every defined priority;
+//   - SetId returns a non-nil Logger and does not change the priority, and
+//     an empty identifier is accepted;
+//   - if lgr has an Instance method, it takes no arguments and returns a
+//     single non-nil value.
+//
+// CheckLogger changes the priority and identifier of lgr, and leaves it with
+// priority Warning and no identifier.  It emits no messages.  The returned
+// error wraps ErrLoggerContract and describes the first violation found.
+func CheckLogger(lgr Logger) error {
+	if lgr == nil {
+		return fmt.Errorf("%w: nil logger", ErrLoggerContract)
+	}
+	if pri := lgr.Priority(); pri != Warning {
+		return fmt.Errorf("%w: default priority %s, not %s", ErrLoggerContract, pri, Warning)
+	}
+	for pri := Emerg; pri <= Debug; pri++ {
+		if lgr.SetPriority(pri) == nil {
+			return fmt.Errorf("%w: SetPriority(%s) returned nil", ErrLoggerContract, pri)
+		}
+		if got := lgr.Priority(); got != pri {
+			return fmt.Errorf("%w: Priority %s after SetPriority(%s)", ErrLoggerContract, got, pri)
+		}
+	}
+	lgr.SetPriority(Warning)
+	for _, id := range []string{"check", ""} {
+		if lgr.SetId(id) == nil {
+			return fmt.Errorf("%w: SetId(%q) returned nil", ErrLoggerContract, id)
+		}
+		if pri := lgr.Priority(); pri != Warning {
+			return fmt.Errorf("%w: SetId(%q) changed priority to %s", ErrLoggerContract, id, pri)
+		}
+	}
+	if m := reflect.ValueOf(lgr).MethodByName("Instance"); m.IsValid() {
+		mt := m.Type()
+		if mt.NumIn() != 0 || mt.NumOut() != 1 {
+			return fmt.Errorf("%w: Instance has signature %s", ErrLoggerContract, mt)
+		}
+		rv := m.Call(nil)[0]
+		switch rv.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			if rv.IsNil() {
+				return fmt.Errorf("%w: Instance returned nil", ErrLoggerContract)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// badLogger violates the Logger contract in ways selected by its fields.
+type badLogger struct {
+	pri      Priority
+	sticky   bool
+	instance *strings.Builder
+}
+
+func (v *badLogger) Priority() Priority {
+	return v.pri
+}
+
+func (v *badLogger) F(pri Priority, format string, args ...interface{}) {}
+
+func (v *badLogger) SetId(id string) Logger {
+	return v
+}
+
+func (v *badLogger) SetPriority(pri Priority) Logger {
+	if !v.sticky {
+		v.pri = pri
+	}
+	return v
+}
+
+func (v *badLogger) Instance() *strings.Builder {
+	return v.instance
+}
+
+func TestCheckLogger(t *testing.T) {
+	for _, lgr := range []Logger{
+		LogLogMaker(nil),
+		NullLogMaker(nil),
+		MakeMaxLineLogger(LogLogMaker(nil), 10),
+		MakeReportLogger(LogLogMaker(nil)),
+		&badLogger{pri: Warning, instance: &strings.Builder{}},
+	} {
+		if err := CheckLogger(lgr); err != nil {
+			t.Errorf("%T: %v", lgr, err)
+		}
+		if pri := lgr.Priority(); pri != Warning {
+			t.Errorf("%T: not restored: %s", lgr, pri)
+		}
+	}
+
+	for _, tc := range []struct {
+		lgr Logger
+		msg string
+	}{
+		{&badLogger{pri: Info}, "default priority Info"},
+		{&badLogger{pri: Warning, sticky: true}, "Priority Warning after SetPriority(Emerg)"},
+		{&badLogger{pri: Warning}, "Instance returned nil"},
+	} {
+		err := CheckLogger(tc.lgr)
+		if !errors.Is(err, ErrLoggerContract) {
+			t.Errorf("wrong error: %v", err)
+		} else if !strings.Contains(err.Error(), tc.msg) {
+			t.Errorf("wrong message: %v", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FCode emits through lgr a message identified by an event code, such as
+// "E1234".  The code is rendered within square brackets at the start of the
+// message, e.g. "[E1234] disk full", so coded events are formatted
+// consistently and can be found by searching for the code.
+func FCode(lgr ImmutableLogger, pri Priority, code string, format string, args ...interface{}) {
+	if lgr.Priority().Enables(pri) {
+		lgr.F(pri, "[%s] %s", code, fmt.Sprintf(format, args...))
+	}
+}
+
+// CodeCatalog records the event codes that have been logged, supporting a
+// run-time catalog of which notable events have occurred.  All methods are
+// safe for concurrent use.
+type CodeCatalog struct {
+	mu    sync.Mutex
+	codes map[string]uint64
+}
+
+// MakeCodeCatalog returns an empty CodeCatalog.
+func MakeCodeCatalog() *CodeCatalog {
+	return &CodeCatalog{
+		codes: make(map[string]uint64),
+	}
+}
+
+// FCode records code in the catalog and emits the message as with the
+// package-level FCode.  The code is recorded even if lgr does not emit the
+// message, since the event occurred regardless of how it was filtered.
+func (c *CodeCatalog) FCode(lgr ImmutableLogger, pri Priority, code string, format string, args ...interface{}) {
+	c.mu.Lock()
+	c.codes[code]++
+	c.mu.Unlock()
+	FCode(lgr, pri, code, format, args...)
+}
+
+// Codes returns a snapshot of the codes recorded in the catalog with the
+// number of times each was logged.
+func (c *CodeCatalog) Codes() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rv := make(map[string]uint64, len(c.codes))
+	for k, n := range c.codes {
+		rv[k] = n
+	}
+	return rv
+}
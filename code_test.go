@@ -0,0 +1,35 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFCode(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lgr.SetPriority(Warning)
+	cat := MakeCodeCatalog()
+
+	FCode(lgr, Error, "E1", "direct %d", 1)
+	cat.FCode(lgr, Error, "E1234", "disk %s full", "/var")
+	cat.FCode(lgr, Info, "I20", "filtered")
+	cat.FCode(lgr, Error, "E1234", "disk %s full", "/tmp")
+
+	exp := "[E] [E1] direct 1\n[E] [E1234] disk /var full\n[E] [E1234] disk /tmp full\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	codes := cat.Codes()
+	if !reflect.DeepEqual(codes, map[string]uint64{"E1234": 2, "I20": 1}) {
+		t.Errorf("wrong catalog: %v", codes)
+	}
+	codes["X"] = 1
+	if _, ok := cat.Codes()["X"]; ok {
+		t.Errorf("snapshot aliases catalog")
+	}
+}
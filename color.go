@@ -0,0 +1,75 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ColorMode controls whether LogLogger uses ANSI escape sequences to color
+// parts of the messages it emits.
+type ColorMode int
+
+const (
+	// ColorNever disables coloring.  This is the default.
+	ColorNever ColorMode = iota
+	// ColorAuto enables coloring only when the output is a terminal.
+	ColorAuto
+	// ColorAlways enables coloring regardless of the output.
+	ColorAlways
+)
+
+// idPalette holds the ANSI foreground color codes used to render
+// identifiers.  Black, white, and their bright variants are excluded for
+// legibility on common terminal backgrounds.
+var idPalette = []int{31, 32, 33, 34, 35, 36, 91, 92, 93, 94, 95, 96}
+
+// idColor returns the ANSI escape sequence that selects the foreground
+// color for id.  The color is derived from a hash of id, so it is stable
+// across processes.
+func idColor(id string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return "\x1b[" + strconv.Itoa(idPalette[h.Sum32()%uint32(len(idPalette))]) + "m"
+}
+
+// colorReset is the ANSI escape sequence that restores the default
+// attributes.
+const colorReset = "\x1b[0m"
+
+// isTerminal returns true if w is an *os.File that refers to a character
+// device.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ttyCache remembers whether the most recently checked output was a terminal
+// so the check is not repeated for every message.
+type ttyCache struct {
+	w     io.Writer
+	isTTY bool
+}
+
+// check returns true if w is a terminal, consulting and updating the cache.
+// Only *os.File values are cached, since they're the only ones that can be
+// terminals and they're safe to compare.
+func (c *ttyCache) check(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	if cf, ok := c.w.(*os.File); !ok || cf != f {
+		c.w = f
+		c.isTTY = isTerminal(f)
+	}
+	return c.isTTY
+}
@@ -0,0 +1,55 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIdColor(t *testing.T) {
+	if idColor("svc") != idColor("svc") {
+		t.Errorf("unstable color")
+	}
+	seen := make(map[string]bool)
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		seen[idColor(id)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("no color variety: %v", seen)
+	}
+}
+
+func TestLogLoggerIdColor(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+	lgr.SetId("svc ")
+
+	ll.SetIdColor(ColorAlways)
+	lgr.F(Info, "colored")
+	if s, exp := sb.String(), idColor("svc ")+"svc "+colorReset+"[I] colored\n"; s != exp {
+		t.Errorf("wrong colored: %q != %q", s, exp)
+	}
+	sb.Reset()
+
+	// A strings.Builder is not a terminal.
+	ll.SetIdColor(ColorAuto)
+	lgr.F(Info, "plain")
+	if s := sb.String(); s != "svc [I] plain\n" {
+		t.Errorf("wrong auto: %q", s)
+	}
+	sb.Reset()
+
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var c ttyCache
+	if c.check(f) || c.w != f || c.check(&sb) {
+		t.Errorf("regular file detected as terminal")
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"reflect"
+)
+
+// CompositeLogMaker returns a LogMaker that delegates to the LogMaker in
+// routes associated with the concrete type of the owner, or to def if there
+// is none.  This packages the type switch of a typical application LogMaker
+// as reusable infrastructure, allowing libraries to provide LogMakers for
+// their own types that the application composes behind a single injected
+// LogMaker.
+//
+// A nil owner has no concrete type and always uses def, but a typed nil
+// pointer is routed by its type.  If def is nil NullLogMaker is used.  The
+// routes map is copied.
+func CompositeLogMaker(routes map[reflect.Type]LogMaker, def LogMaker) LogMaker {
+	if def == nil {
+		def = NullLogMaker
+	}
+	rm := make(map[reflect.Type]LogMaker, len(routes))
+	for t, mk := range routes {
+		rm[t] = mk
+	}
+	return func(owner interface{}) Logger {
+		if owner != nil {
+			if mk, ok := rm[reflect.TypeOf(owner)]; ok {
+				return mk(owner)
+			}
+		}
+		return def(owner)
+	}
+}
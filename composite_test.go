@@ -0,0 +1,45 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type compositeOwnerA struct{}
+type compositeOwnerB struct{}
+
+func TestCompositeLogMaker(t *testing.T) {
+	var sba, sbd strings.Builder
+	mkA := func(interface{}) Logger {
+		return newTestLogger(&sba).SetId("A ")
+	}
+	mkD := func(interface{}) Logger {
+		return newTestLogger(&sbd).SetId("D ")
+	}
+	routes := map[reflect.Type]LogMaker{
+		reflect.TypeOf(&compositeOwnerA{}): mkA,
+	}
+	mk := CompositeLogMaker(routes, mkD)
+	delete(routes, reflect.TypeOf(&compositeOwnerA{}))
+
+	mk(&compositeOwnerA{}).F(Info, "a")
+	mk((*compositeOwnerA)(nil)).F(Info, "typed nil")
+	mk(&compositeOwnerB{}).F(Info, "b")
+	mk(compositeOwnerA{}).F(Info, "value")
+	mk(nil).F(Info, "nil")
+
+	if s := sba.String(); s != "A [I] a\nA [I] typed nil\n" {
+		t.Errorf("wrong routed output: %q", s)
+	}
+	if s := sbd.String(); s != "D [I] b\nD [I] value\nD [I] nil\n" {
+		t.Errorf("wrong default output: %q", s)
+	}
+
+	if p := CompositeLogMaker(nil, nil)(nil).Priority(); p != Warning {
+		t.Errorf("wrong null default: %s", p)
+	}
+}
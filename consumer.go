@@ -0,0 +1,180 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// EmitterPrefix returns the prefix of the channel logger that submitted e,
+// as provided to PrefixedChanLogger.  It returns an empty string if e has
+// no prefix or was not submitted by a channel logger.  This is suitable as a
+// grouping key for Consumer.SetGrouping.
+func EmitterPrefix(e Emitter) string {
+	if m, ok := e.(*emittable); ok {
+		return m.pfx
+	}
+	return ""
+}
+
+// EmitterContextValue returns the context value submitted with e through
+// FContextValue, or nil if there is none or e was not submitted by a
+// channel logger.  This allows a consumer to inspect the value before
+// emitting the message.
+func EmitterContextValue(e Emitter) interface{} {
+	if m, ok := e.(*emittable); ok {
+		return m.cv
+	}
+	return nil
+}
+
+// Consumer emits the messages received from a channel returned by
+// MakeChanLogger.  By default each message is emitted as soon as it is
+// received, preserving the order of submission.
+type Consumer struct {
+	ech    <-chan Emitter
+	window time.Duration
+	key    func(Emitter) string
+}
+
+// MakeConsumer returns a Consumer that emits messages from ech.
+func MakeConsumer(ech <-chan Emitter) *Consumer {
+	return &Consumer{
+		ech: ech,
+	}
+}
+
+// SetGrouping causes the consumer to collect messages for window after the
+// first message of a batch is received, then emit the batch grouped by the
+// value returned by key, e.g. EmitterPrefix.  Groups appear in the order in
+// which their first message was received, and messages within a group keep
+// their order.  This makes output from several producers sharing a channel
+// easier to read when it is reviewed in batches.
+//
+// Grouping trades latency for readability: each message is delayed by up
+// to window.  A window that is not positive, or a nil key, restores
+// immediate emission in order of submission, which is the default.  This
+// must not be invoked while Run is executing.
+func (c *Consumer) SetGrouping(window time.Duration, key func(Emitter) string) *Consumer {
+	if key == nil {
+		window = 0
+	}
+	c.window = window
+	c.key = key
+	return c
+}
+
+// Run emits messages until ctx is done or the channel is closed.  Any batch
+// being collected for grouping is emitted before Run returns, but messages
+// remaining in the channel are not.
+func (c *Consumer) Run(ctx context.Context) {
+	if c.window <= 0 {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-c.ech:
+				if !ok {
+					return
+				}
+				e.Emit()
+			}
+		}
+	}
+	var batch []Emitter
+	var timeout <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			c.emitGrouped(batch)
+			return
+		case e, ok := <-c.ech:
+			if !ok {
+				c.emitGrouped(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timeout = time.After(c.window)
+			}
+			batch = append(batch, e)
+		case <-timeout:
+			c.emitGrouped(batch)
+			batch = batch[:0]
+			timeout = nil
+		}
+	}
+}
+
+// emitGrouped emits batch grouped by key.
+func (c *Consumer) emitGrouped(batch []Emitter) {
+	first := make(map[string]int)
+	rank := make([]int, len(batch))
+	for i, e := range batch {
+		k := c.key(e)
+		r, ok := first[k]
+		if !ok {
+			r = len(first)
+			first[k] = r
+		}
+		rank[i] = r
+	}
+	idx := make([]int, len(batch))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return rank[idx[i]] < rank[idx[j]]
+	})
+	for _, i := range idx {
+		batch[i].Emit()
+	}
+}
+
+// RunAndFlush emits messages from ech in order of submission until ctx is
+// done or ech is closed.  If ctx is done first, it then spends up to
+// drainTimeout emitting messages already buffered in ech, stopping early
+// when the channel is empty or closed.  This is the usual shutdown sequence
+// for a channel logger's consumer: stop waiting for new messages, but don't
+// lose the ones already submitted.
+//
+// The return value is the number of messages emitted during the final
+// drain, which the caller may wish to log.  A drainTimeout that is not
+// positive drains nothing.
+func RunAndFlush(ctx context.Context, ech <-chan Emitter, drainTimeout time.Duration) int {
+	for {
+		if ctx.Err() != nil {
+			return drainUntil(ech, time.Now().Add(drainTimeout))
+		}
+		select {
+		case <-ctx.Done():
+			return drainUntil(ech, time.Now().Add(drainTimeout))
+		case e, ok := <-ech:
+			if !ok {
+				return 0
+			}
+			e.Emit()
+		}
+	}
+}
+
+// drainUntil emits buffered messages from ech until it is empty or closed,
+// or deadline is reached, and returns the number emitted.
+func drainUntil(ech <-chan Emitter, deadline time.Time) int {
+	n := 0
+	for time.Now().Before(deadline) {
+		select {
+		case e, ok := <-ech:
+			if !ok {
+				return n
+			}
+			e.Emit()
+			n++
+		default:
+			return n
+		}
+	}
+	return n
+}
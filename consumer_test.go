@@ -0,0 +1,169 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmitterPrefix(t *testing.T) {
+	var sb strings.Builder
+	lgr, lch := MakeChanLogger(newTestLogger(&sb), 4)
+	PrefixedChanLogger(lgr, "p: ").F(Info, "m")
+	FRequest(PrefixedChanLogger(lgr, "q: "), "r1", Info, "m")
+	lgr.F(Info, "m")
+	for _, exp := range []string{"p: ", "q: ", ""} {
+		if p := EmitterPrefix(<-lch); p != exp {
+			t.Errorf("wrong prefix %q, expected %q", p, exp)
+		}
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	var sb strings.Builder
+	lgr, lch := MakeChanLogger(newTestLogger(&sb), 8)
+	a := PrefixedChanLogger(lgr, "a: ")
+	b := PrefixedChanLogger(lgr, "b: ")
+
+	a.F(Info, "1")
+	b.F(Info, "2")
+	a.F(Info, "3")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		MakeConsumer(lch).Run(ctx)
+		close(done)
+	}()
+	<-FDone(b, Info, "4")
+	cancel()
+	<-done
+	if s := sb.String(); s != "[I] a: 1\n[I] b: 2\n[I] a: 3\n[I] b: 4\n" {
+		t.Errorf("wrong FIFO output: %q", s)
+	}
+	sb.Reset()
+
+	a.F(Info, "1")
+	b.F(Info, "2")
+	a.F(Info, "3")
+	lgr.F(Info, "4")
+	b.F(Info, "5")
+	ctx, cancel = context.WithCancel(context.Background())
+	done = make(chan struct{})
+	go func() {
+		MakeConsumer(lch).SetGrouping(time.Hour, EmitterPrefix).Run(ctx)
+		close(done)
+	}()
+	for len(lch) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if s := sb.String(); s != "" {
+		t.Errorf("emitted before window: %q", s)
+	}
+	cancel()
+	<-done
+	if s := sb.String(); s != "[I] a: 1\n[I] a: 3\n[I] b: 2\n[I] b: 5\n[I] 4\n" {
+		t.Errorf("wrong grouped output: %q", s)
+	}
+	sb.Reset()
+
+	a.F(Info, "1")
+	b.F(Info, "2")
+	a.F(Info, "3")
+	// Queued before the consumer starts, so all four messages are in the
+	// same batch; completes only once the window expires.
+	fd := FDone(a, Info, "4")
+	ctx, cancel = context.WithCancel(context.Background())
+	done = make(chan struct{})
+	go func() {
+		MakeConsumer(lch).SetGrouping(10*time.Millisecond, EmitterPrefix).Run(ctx)
+		close(done)
+	}()
+	<-fd
+	cancel()
+	<-done
+	if s := sb.String(); s != "[I] a: 1\n[I] a: 3\n[I] a: 4\n[I] b: 2\n" {
+		t.Errorf("wrong windowed output: %q", s)
+	}
+}
+
+func TestRunAndFlush(t *testing.T) {
+	var sb strings.Builder
+	lgr, lch := MakeChanLogger(newTestLogger(&sb), 8)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() {
+		done <- RunAndFlush(ctx, lch, time.Minute)
+	}()
+	<-FDone(lgr, Info, "live")
+	cancel()
+	if n := <-done; n != 0 {
+		t.Errorf("wrong idle drain count: %d", n)
+	}
+
+	lgr.F(Info, "1")
+	lgr.F(Info, "2")
+	lgr.F(Info, "3")
+	if n := RunAndFlush(ctx, lch, time.Minute); n != 3 {
+		t.Errorf("wrong drain count: %d", n)
+	}
+	lgr.F(Info, "4")
+	if n := RunAndFlush(ctx, lch, 0); n != 0 {
+		t.Errorf("wrong zero-timeout drain count: %d", n)
+	}
+	if s := sb.String(); s != "[I] live\n[I] 1\n[I] 2\n[I] 3\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestMessageEmitter(t *testing.T) {
+	var sb strings.Builder
+	lgr, lch := MakeChanLogger(newTestLogger(&sb), 4)
+	plgr := PrefixedChanLogger(lgr, "p: ")
+
+	plgr.F(Warning, "value %d", 3)
+	FRequest(plgr, "r1", Info, "req")
+	b := StartBlock(lgr)
+	b.F(Info, "one")
+	b.F(Error, "two")
+	b.Close()
+
+	exp := []struct {
+		pri Priority
+		msg string
+	}{
+		{Warning, "p: value 3"},
+		{Info, "p: [r1] req"},
+		{Error, "one\ntwo"},
+	}
+	var es []Emitter
+	for len(lch) > 0 {
+		es = append(es, <-lch)
+	}
+	if len(es) != len(exp) {
+		t.Fatalf("wrong emitter count: %d", len(es))
+	}
+	for i, x := range exp {
+		me, ok := es[i].(MessageEmitter)
+		if !ok {
+			t.Fatalf("%d: not a MessageEmitter", i)
+		}
+		if p := me.Priority(); p != x.pri {
+			t.Errorf("%d: wrong priority: %s", i, p)
+		}
+		if m := me.Message(); m != x.msg {
+			t.Errorf("%d: wrong message: %q", i, m)
+		}
+		// Reroute the message.
+		lgr.F(me.Priority(), "rerouted %s", me.Message())
+		(<-lch).Emit()
+	}
+	exp2 := "[W] rerouted p: value 3\n[I] rerouted p: [r1] req\n[E] rerouted one\ntwo\n"
+	if s := sb.String(); s != exp2 {
+		t.Errorf("wrong output: %q", s)
+	}
+}
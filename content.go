@@ -0,0 +1,75 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// PriorityRule reassigns the priority of messages that match Pattern to To.
+type PriorityRule struct {
+	Pattern *regexp.Regexp
+	To      Priority
+}
+
+// ContentPriorityLogger wraps a Logger to change the priority of messages
+// based on their formatted text.  This allows correcting the severity of
+// messages from a dependency that logs routine events as errors, or that
+// hides important ones at Debug.
+//
+// Each message is compared against the rules in order, and the first rule
+// with a matching pattern determines the priority.  Messages that match no
+// rule keep their original priority.  The priority filter is applied after
+// reassignment, so every message must be formatted to be checked.
+type ContentPriorityLogger struct {
+	lgr   Logger
+	rules []PriorityRule
+}
+
+// MakeContentPriorityLogger returns a ContentPriorityLogger that applies
+// rules to messages before forwarding them to lgr.  The rules slice is
+// copied.
+func MakeContentPriorityLogger(lgr Logger, rules []PriorityRule) *ContentPriorityLogger {
+	return &ContentPriorityLogger{
+		lgr:   lgr,
+		rules: append([]PriorityRule(nil), rules...),
+	}
+}
+
+// Priority per ImmutableLogger.
+func (v *ContentPriorityLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *ContentPriorityLogger) F(pri Priority, format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	for _, r := range v.rules {
+		if r.Pattern.MatchString(s) {
+			pri = r.To
+			break
+		}
+	}
+	if v.lgr.Priority().Enables(pri) {
+		v.lgr.F(pri, "%s", s)
+	}
+}
+
+// Audit per Auditor.  Audit messages are not remapped.
+func (v *ContentPriorityLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *ContentPriorityLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *ContentPriorityLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,36 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestContentPriorityLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeContentPriorityLogger(newTestLogger(&sb).SetPriority(Warning), []PriorityRule{
+		{regexp.MustCompile(`^connection reset`), Debug},
+		{regexp.MustCompile(`corrupt`), Crit},
+		{regexp.MustCompile(`reset`), Error},
+	})
+
+	lgr.F(Error, "connection reset by %s", "peer")
+	lgr.F(Debug, "block %d corrupt", 7)
+	lgr.F(Info, "counter reset")
+	lgr.F(Warning, "unmatched")
+	lgr.F(Info, "unmatched")
+	exp := "[C] block 7 corrupt\n[E] counter reset\n[W] unmatched\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	sb.Reset()
+
+	lgr.SetId("id ").SetPriority(Debug)
+	lgr.F(Error, "connection reset")
+	if s := sb.String(); s != "id [D] connection reset\n" {
+		t.Errorf("wrong downgrade: %q", s)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextFieldExtractor copies values stored in a context.Context into the
+// fields of a message, so request metadata such as a request identifier or
+// tenant that is propagated through contexts appears in the log without
+// being copied into fields at every call site.
+//
+// Each registered context key is associated with a field name.  Loggers
+// returned by Bind look up every registered key in their context when a
+// message is emitted.  Each lookup is a ctx.Value call, which walks the
+// chain of contexts derived from the one holding the value, and the fields
+// are collected in a new map, so the cost of each emitted message grows
+// with the number of registered keys and the depth of the context.
+// Messages that are filtered by priority incur no extraction cost.
+//
+// All methods are safe for concurrent use.
+type ContextFieldExtractor struct {
+	mu   sync.RWMutex
+	keys []contextField
+}
+
+// contextField associates a context key with a field name.
+type contextField struct {
+	key  interface{}
+	name string
+}
+
+// MakeContextFieldExtractor returns a ContextFieldExtractor with no
+// registered keys.
+func MakeContextFieldExtractor() *ContextFieldExtractor {
+	return &ContextFieldExtractor{}
+}
+
+// Register arranges for the value stored in a context under key to be
+// emitted as the field name.  Registering a key again replaces its field
+// name.
+func (x *ContextFieldExtractor) Register(key interface{}, name string) *ContextFieldExtractor {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for i := range x.keys {
+		if x.keys[i].key == key {
+			x.keys[i].name = name
+			return x
+		}
+	}
+	x.keys = append(x.keys, contextField{key, name})
+	return x
+}
+
+// Fields returns the fields for the registered keys that have a value in
+// ctx, or nil if there are none.
+func (x *ContextFieldExtractor) Fields(ctx context.Context) Fields {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	var rv Fields
+	for _, cf := range x.keys {
+		if val := ctx.Value(cf.key); val != nil {
+			if rv == nil {
+				rv = make(Fields, len(x.keys))
+			}
+			rv[cf.name] = val
+		}
+	}
+	return rv
+}
+
+// Bind returns a logger that emits messages through lgr with the fields
+// extracted from ctx, as with FF.  The returned logger implements
+// FieldLogger; fields passed to its FF method replace extracted fields with
+// the same name.  Keys registered after Bind is invoked are extracted from
+// subsequent messages.
+func (x *ContextFieldExtractor) Bind(lgr ImmutableLogger, ctx context.Context) FieldLogger {
+	return &contextFieldLogger{
+		lgr: lgr,
+		ctx: ctx,
+		x:   x,
+	}
+}
+
+// contextFieldLogger adds fields extracted from its context to messages.
+type contextFieldLogger struct {
+	lgr ImmutableLogger
+	ctx context.Context
+	x   *ContextFieldExtractor
+}
+
+// Priority per ImmutableLogger.
+func (v *contextFieldLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *contextFieldLogger) F(pri Priority, format string, args ...interface{}) {
+	v.FF(pri, nil, format, args...)
+}
+
+// Audit per Auditor.  Context fields are not added to audit messages.
+func (v *contextFieldLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// FF per FieldLogger.
+func (v *contextFieldLogger) FF(pri Priority, fields Fields, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	cf := v.x.Fields(v.ctx)
+	if len(fields) != 0 {
+		cf, _ = MergeFields(cf, fields)
+	}
+	if len(cf) == 0 {
+		v.lgr.F(pri, format, args...)
+		return
+	}
+	FF(v.lgr, pri, cf, format, args...)
+}
@@ -0,0 +1,44 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type ctxFieldKey string
+
+func TestContextFieldExtractor(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+
+	x := MakeContextFieldExtractor().
+		Register(ctxFieldKey("rid"), "request").
+		Register(ctxFieldKey("tenant"), "tenant")
+	ctx := context.WithValue(context.Background(), ctxFieldKey("rid"), "r42")
+
+	if f := x.Fields(context.Background()); f != nil {
+		t.Errorf("unexpected fields: %v", f)
+	}
+	lgr := x.Bind(blgr, ctx)
+	lgr.F(Info, "one %d", 1)
+	ctx = context.WithValue(ctx, ctxFieldKey("tenant"), "acme")
+	lgr = x.Bind(blgr, ctx)
+	FF(lgr, Info, Fields{"request": "r43", "n": 2}, "two")
+	x.Register(ctxFieldKey("tenant"), "org")
+	lgr.F(Info, "three")
+	x.Bind(blgr, context.Background()).F(Info, "bare")
+	blgr.SetPriority(Warning)
+	lgr.F(Info, "filtered")
+
+	exp := "[I] one 1 request=r42\n" +
+		"[I] two n=2 request=r43 tenant=acme\n" +
+		"[I] three org=acme request=r42\n" +
+		"[I] bare\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+}
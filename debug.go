@@ -0,0 +1,24 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nodebug
+
+package logwrap
+
+// DebugEnabled is false if the package was built with the nodebug build
+// tag, in which case FDebug discards its messages without formatting them.
+const DebugEnabled = true
+
+// FDebug emits a message through lgr at Debug priority.
+//
+// Debug messages in performance-critical code can be compiled out by
+// building with the nodebug tag, e.g. "go build -tags nodebug".  In that
+// build FDebug is an empty function that the compiler inlines and
+// eliminates along with the boxing of its arguments, so the message is
+// neither formatted nor allocated.  The arguments themselves are still
+// evaluated, as Go evaluates them before the call, so expressions with a
+// significant cost or side effects should not be passed.  Debug messages emitted any other way, e.g. with
+// lgr.F or the D member of PriPr, are not affected by the tag.
+func FDebug(lgr ImmutableLogger, format string, args ...interface{}) {
+	lgr.F(Debug, format, args...)
+}
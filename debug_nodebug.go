@@ -0,0 +1,15 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nodebug
+
+package logwrap
+
+// DebugEnabled is false if the package was built with the nodebug build
+// tag, in which case FDebug discards its messages without formatting them.
+const DebugEnabled = false
+
+// FDebug discards its message: the package was built with the nodebug
+// tag.
+func FDebug(lgr ImmutableLogger, format string, args ...interface{}) {
+}
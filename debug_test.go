@@ -0,0 +1,47 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFDebug(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	FDebug(lgr, "direct %d", 1)
+	exp := "[D] direct 1\n"
+	if !DebugEnabled {
+		exp = ""
+	}
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+
+	if !DebugEnabled {
+		lgr.SetPriority(Debug)
+		n := 1000
+		allocs := testing.AllocsPerRun(100, func() {
+			FDebug(lgr, "message %d %s", n, "text")
+		})
+		if allocs != 0 {
+			t.Errorf("stripped FDebug allocates: %v", allocs)
+		}
+	}
+}
+
+// BenchmarkFDebug measures a Debug message that the logger would emit.
+// Run with -tags nodebug to compare with the stripped path, which
+// TestFDebug verifies does not allocate.
+func BenchmarkFDebug(b *testing.B) {
+	lgr := LogLogMaker(nil).SetPriority(Debug)
+	lgr.(*LogLogger).Instance().SetOutput(io.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FDebug(lgr, "message %d %s", i, "text")
+	}
+}
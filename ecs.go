@@ -0,0 +1,164 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ECSVersion is the version of the Elastic Common Schema recorded in the
+// ecs.version field of messages from ECSLogMaker loggers.
+const ECSVersion = "8.11.0"
+
+// DefaultECSIdField is the ECS field that holds the identifier assigned
+// with SetId to ECSLogMaker loggers unless changed with SetIdField.
+const DefaultECSIdField = "log.logger"
+
+// ECSTimeFormat is the layout of the @timestamp field: ISO 8601 in UTC with
+// millisecond resolution.
+const ECSTimeFormat = "2006-01-02T15:04:05.000Z"
+
+var ecsLevelMap = map[Priority]string{
+	Emerg:   "emergency",
+	Crit:    "critical",
+	Error:   "error",
+	Warning: "warning",
+	Notice:  "notice",
+	Info:    "info",
+	Debug:   "debug",
+}
+
+// ECSLevel returns the value of the log.level field for messages emitted at
+// pri, which is the lower-case syslog severity name.
+func ECSLevel(pri Priority) string {
+	return ecsLevelMap[pri]
+}
+
+// ECSLogMaker returns a LogMaker that creates loggers that write each
+// message to w as a single line of JSON using Elastic Common Schema field
+// names, so it can be ingested by Elasticsearch without a transform stage.
+// Each object has @timestamp per ECSTimeFormat, log.level per ECSLevel,
+// log.syslog.severity.code holding the syslog level, message, and
+// ecs.version.  The identifier, if any, is placed in the field selected by
+// ECSLogger.SetIdField.
+//
+// Created loggers implement FieldLogger; fields are added to the object
+// with their keys as given, and should use ECS names where one applies.
+// Values of fields with keys in DefaultSensitiveKeys are replaced by
+// MaskedValue.  Fields do not replace the standard fields, and are omitted if any of
+// them cannot be encoded as JSON.  Writes to w from all loggers created by
+// the LogMaker are serialized, and each message is written with a single
+// Write call.  Created loggers have priority Warning.
+func ECSLogMaker(w io.Writer) LogMaker {
+	mu := &sync.Mutex{}
+	return func(interface{}) Logger {
+		return &ECSLogger{
+			w:       w,
+			wmu:     mu,
+			pri:     Warning,
+			idField: DefaultECSIdField,
+		}
+	}
+}
+
+// ECSLogger emits messages as Elastic Common Schema JSON objects.  All
+// methods are safe for concurrent use.
+type ECSLogger struct {
+	w       io.Writer
+	wmu     *sync.Mutex
+	mu      sync.Mutex
+	id      string
+	idField string
+	pri     Priority
+}
+
+// SetIdField selects the ECS field that holds the identifier, e.g.
+// "service.name".  Passing an empty string restores DefaultECSIdField.
+func (v *ECSLogger) SetIdField(name string) *ECSLogger {
+	if name == "" {
+		name = DefaultECSIdField
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.idField = name
+	return v
+}
+
+// Priority per ImmutableLogger.
+func (v *ECSLogger) Priority() Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pri
+}
+
+// F per ImmutableLogger.
+func (v *ECSLogger) F(pri Priority, format string, args ...interface{}) {
+	v.FF(pri, nil, format, args...)
+}
+
+// FF per FieldLogger.
+func (v *ECSLogger) FF(pri Priority, fields Fields, format string, args ...interface{}) {
+	v.mu.Lock()
+	id, idField, enabled := v.id, v.idField, v.pri.Enables(pri)
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	fields = defaultSensitiveKeys.mask(fields)
+	obj := make(map[string]interface{}, len(fields)+6)
+	for k, fv := range fields {
+		obj[k] = fv
+	}
+	obj["@timestamp"] = time.Now().UTC().Format(ECSTimeFormat)
+	obj["log.level"] = ECSLevel(pri)
+	obj["log.syslog.severity.code"] = pri.Level()
+	obj["message"] = fmt.Sprintf(format, args...)
+	obj["ecs.version"] = ECSVersion
+	if id != "" {
+		obj[idField] = id
+	}
+	buf, err := json.Marshal(obj)
+	if err != nil {
+		// A field value could not be encoded; emit without fields.
+		for k := range fields {
+			if _, std := ecsStandardFields[k]; !std && k != idField {
+				delete(obj, k)
+			}
+		}
+		buf, _ = json.Marshal(obj)
+	}
+	buf = append(buf, '\n')
+	v.wmu.Lock()
+	defer v.wmu.Unlock()
+	_, _ = v.w.Write(buf)
+}
+
+// ecsStandardFields are the fields always set by ECSLogger.
+var ecsStandardFields = map[string]struct{}{
+	"@timestamp":               {},
+	"log.level":                {},
+	"log.syslog.severity.code": {},
+	"message":                  {},
+	"ecs.version":              {},
+}
+
+// SetId per Logger.
+func (v *ECSLogger) SetId(id string) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.id = id
+	return v
+}
+
+// SetPriority per Logger.
+func (v *ECSLogger) SetPriority(pri Priority) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
@@ -0,0 +1,87 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestECSLevel(t *testing.T) {
+	exp := map[Priority]string{
+		Emerg:   "emergency",
+		Crit:    "critical",
+		Error:   "error",
+		Warning: "warning",
+		Notice:  "notice",
+		Info:    "info",
+		Debug:   "debug",
+	}
+	for pri, s := range exp {
+		if l := ECSLevel(pri); l != s {
+			t.Errorf("%s: got %s, expected %s", pri, l, s)
+		}
+	}
+}
+
+func TestECSLogMaker(t *testing.T) {
+	var sb strings.Builder
+	mk := ECSLogMaker(&sb)
+	lgr := mk(nil)
+	lgr.SetId("svc")
+
+	t0 := time.Now().UTC().Truncate(time.Millisecond)
+	lgr.F(Error, "disk %s full", "/var")
+	lgr.F(Info, "filtered")
+	lgr.(*ECSLogger).SetIdField("service.name")
+	FF(lgr, Warning, Fields{"host.name": "h1", "message": "ignored", "Password": "pw"}, "warn")
+	FF(lgr, Warning, Fields{"bad": make(chan int)}, "unencodable")
+	t1 := time.Now().UTC()
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wrong line count: %q", sb.String())
+	}
+	var objs []map[string]interface{}
+	for _, l := range lines {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(l), &obj); err != nil {
+			t.Fatalf("bad JSON %q: %v", l, err)
+		}
+		objs = append(objs, obj)
+	}
+
+	o := objs[0]
+	ts, err := time.Parse(ECSTimeFormat, o["@timestamp"].(string))
+	if err != nil || ts.Before(t0) || ts.After(t1) {
+		t.Errorf("bad timestamp %v: %v", o["@timestamp"], err)
+	}
+	if !strings.HasSuffix(o["@timestamp"].(string), "Z") {
+		t.Errorf("timestamp not UTC: %v", o["@timestamp"])
+	}
+	if o["log.level"] != "error" || o["log.syslog.severity.code"] != 3.0 {
+		t.Errorf("wrong level: %v", o)
+	}
+	if o["message"] != "disk /var full" || o["log.logger"] != "svc" || o["ecs.version"] != ECSVersion {
+		t.Errorf("wrong fields: %v", o)
+	}
+
+	o = objs[1]
+	if o["service.name"] != "svc" || o["host.name"] != "h1" || o["message"] != "warn" {
+		t.Errorf("wrong second: %v", o)
+	}
+	if _, ok := o["log.logger"]; ok {
+		t.Errorf("id in default field: %v", o)
+	}
+	if o["Password"] != MaskedValue {
+		t.Errorf("sensitive field not masked: %v", o)
+	}
+
+	o = objs[2]
+	if _, ok := o["bad"]; ok || o["message"] != "unencodable" {
+		t.Errorf("wrong fallback: %v", o)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultEmissionDepth is the number of emission times retained per
+// priority by an EmissionLogger when no depth is specified.
+const DefaultEmissionDepth = 256
+
+// timeRing retains the most recent times added to it.
+type timeRing struct {
+	times []time.Time
+	next  int
+}
+
+// add records t, replacing the oldest time if the ring is full.
+func (r *timeRing) add(t time.Time, depth int) {
+	if len(r.times) < depth {
+		r.times = append(r.times, t)
+		return
+	}
+	r.times[r.next] = t
+	r.next = (r.next + 1) % depth
+}
+
+// snapshot returns the retained times, oldest first.
+func (r *timeRing) snapshot() []time.Time {
+	rv := make([]time.Time, 0, len(r.times))
+	rv = append(rv, r.times[r.next:]...)
+	return append(rv, r.times[:r.next]...)
+}
+
+// EmissionLogger wraps a Logger to record the times at which it emits
+// messages, retaining a bounded number of the most recent times for each
+// priority.  The times can be retrieved to analyze logging rates and
+// burstiness offline, without instrumenting call sites.  It observes
+// logging behavior; it does not limit it.
+//
+// Only messages that pass the priority filter are recorded.  Recording
+// costs a clock read and a mutex acquisition per emitted message.  All
+// methods are safe for concurrent use if the wrapped logger is.
+type EmissionLogger struct {
+	lgr   Logger
+	depth int
+	mu    sync.Mutex
+	rings map[Priority]*timeRing
+}
+
+// MakeEmissionLogger returns an EmissionLogger that forwards to lgr and
+// retains up to depth emission times for each priority.  Values of depth
+// less than 1 are replaced by DefaultEmissionDepth.
+func MakeEmissionLogger(lgr Logger, depth int) *EmissionLogger {
+	if depth < 1 {
+		depth = DefaultEmissionDepth
+	}
+	return &EmissionLogger{
+		lgr:   lgr,
+		depth: depth,
+		rings: make(map[Priority]*timeRing),
+	}
+}
+
+// Snapshot returns a copy of the retained emission times for each priority
+// at which messages have been emitted, oldest first.
+func (v *EmissionLogger) Snapshot() map[Priority][]time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rv := make(map[Priority][]time.Time, len(v.rings))
+	for pri, r := range v.rings {
+		rv[pri] = r.snapshot()
+	}
+	return rv
+}
+
+// Reset discards the retained emission times.
+func (v *EmissionLogger) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rings = make(map[Priority]*timeRing)
+}
+
+// Priority per ImmutableLogger.
+func (v *EmissionLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *EmissionLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.record(pri, time.Now())
+	v.lgr.F(pri, format, args...)
+}
+
+// FGuarded per GuardedLogger.  The emission is recorded only if the
+// wrapped logger emits the message, even if its priority is changed
+// concurrently, provided it implements GuardedLogger.
+func (v *EmissionLogger) FGuarded(pri Priority, msg func() string) {
+	FGuarded(v.lgr, pri, func() string {
+		v.record(pri, time.Now())
+		return msg()
+	})
+}
+
+// record adds t to the emission times for pri.
+func (v *EmissionLogger) record(pri Priority, t time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	r := v.rings[pri]
+	if r == nil {
+		r = &timeRing{}
+		v.rings[pri] = r
+	}
+	r.add(t, v.depth)
+}
+
+// Audit per Auditor.  Audit messages are not recorded as emissions.
+func (v *EmissionLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *EmissionLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *EmissionLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
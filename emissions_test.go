@@ -0,0 +1,46 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmissionLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeEmissionLogger(newTestLogger(&sb), 3)
+	lgr.SetPriority(Info)
+
+	for i := 0; i < 5; i++ {
+		lgr.F(Info, "info %d", i)
+	}
+	lgr.F(Error, "error")
+	lgr.F(Debug, "filtered")
+
+	snap := lgr.Snapshot()
+	if len(snap) != 2 {
+		t.Errorf("wrong priorities: %v", snap)
+	}
+	if n := len(snap[Error]); n != 1 {
+		t.Errorf("wrong error count: %d", n)
+	}
+	times := snap[Info]
+	if len(times) != 3 {
+		t.Fatalf("wrong info count: %d", len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		if times[i].Before(times[i-1]) {
+			t.Errorf("times not ordered: %v", times)
+		}
+	}
+	if n := strings.Count(sb.String(), "\n"); n != 6 {
+		t.Errorf("wrong emission count: %d", n)
+	}
+
+	lgr.Reset()
+	if snap := lgr.Snapshot(); len(snap) != 0 {
+		t.Errorf("reset failed: %v", snap)
+	}
+}
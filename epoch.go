@@ -0,0 +1,67 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// EpochLogger wraps a Logger to prefix each message with a configuration
+// epoch, rendered like "(e5) ".  The epoch starts at zero and advances
+// every time SetPriority is invoked, so operators can tell which
+// configuration window produced a message even when messages from
+// concurrent goroutines are interleaved.
+//
+// All methods are safe for concurrent use if the underlying logger is.
+type EpochLogger struct {
+	lgr   Logger
+	epoch uint64
+}
+
+// MakeEpochLogger returns an EpochLogger at epoch zero that forwards
+// messages to lgr.
+func MakeEpochLogger(lgr Logger) *EpochLogger {
+	return &EpochLogger{
+		lgr: lgr,
+	}
+}
+
+// Epoch returns the current epoch.
+func (v *EpochLogger) Epoch() uint64 {
+	return atomic.LoadUint64(&v.epoch)
+}
+
+// Priority per ImmutableLogger.
+func (v *EpochLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  Messages are formatted only if the underlying
+// logger would emit them.
+func (v *EpochLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.lgr.F(pri, "(e%d) %s", v.Epoch(), fmt.Sprintf(format, args...))
+}
+
+// Audit per Auditor.  The epoch is added as with F.
+func (v *EpochLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, "(e%d) %s", v.Epoch(), fmt.Sprintf(format, args...))
+}
+
+// SetId per Logger.
+func (v *EpochLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.  This changes the underlying logger's priority
+// and advances the epoch, even if pri equals the current priority.
+func (v *EpochLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	atomic.AddUint64(&v.epoch, 1)
+	return v
+}
@@ -0,0 +1,52 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEpochLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeEpochLogger(newTestLogger(&sb))
+
+	if e := lgr.Epoch(); e != 0 {
+		t.Errorf("wrong initial epoch: %d", e)
+	}
+	lgr.F(Info, "start %d", 1)
+	for i := uint64(1); i <= 3; i++ {
+		lgr.SetPriority(Info)
+		if e := lgr.Epoch(); e != i {
+			t.Errorf("epoch did not advance: %d != %d", e, i)
+		}
+	}
+	lgr.F(Debug, "filtered")
+	lgr.F(Info, "later")
+
+	exp := "[I] (e0) start 1\n[I] (e3) later\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestEpochLoggerConcurrent(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeEpochLogger(newTestLogger(&sb))
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lgr.SetPriority(Debug)
+		}()
+	}
+	wg.Wait()
+	if e := lgr.Epoch(); e != n {
+		t.Errorf("wrong epoch: %d", e)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RootCauseField is the field used by ErrorFields for the innermost error
+// of a chain.
+const RootCauseField = "root"
+
+// ErrorChain returns err followed by each error obtained by repeatedly
+// applying errors.Unwrap, ending with the innermost error.  It returns nil
+// if err is nil.
+func ErrorChain(err error) []error {
+	var rv []error
+	for err != nil {
+		rv = append(rv, err)
+		err = errors.Unwrap(err)
+	}
+	return rv
+}
+
+// ErrorLayers returns the text contributed by each error in the chain of
+// err.  Where an error's text ends with ": " followed by the text of the
+// error it wraps, as produced by fmt.Errorf with "%w", only the leading
+// context is returned for that layer.  It returns nil if err is nil.
+func ErrorLayers(err error) []string {
+	chain := ErrorChain(err)
+	rv := make([]string, len(chain))
+	for i, e := range chain {
+		s := e.Error()
+		if i+1 < len(chain) {
+			s = strings.TrimSuffix(s, ": "+chain[i+1].Error())
+		}
+		rv[i] = s
+	}
+	if len(rv) == 0 {
+		return nil
+	}
+	return rv
+}
+
+// ErrorFields returns fields describing the chain of err: "cause0" holds the
+// outermost layer as produced by ErrorLayers, "cause1" the layer it wraps,
+// and so on.  RootCauseField holds the innermost error along with its type,
+// e.g. "EOF (*errors.errorString)", so sentinel errors can be identified
+// without being confused with the context that wraps them.  It returns nil
+// if err is nil.
+func ErrorFields(err error) Fields {
+	layers := ErrorLayers(err)
+	if layers == nil {
+		return nil
+	}
+	rv := make(Fields, len(layers)+1)
+	for i, s := range layers {
+		rv[fmt.Sprintf("cause%d", i)] = s
+	}
+	chain := ErrorChain(err)
+	root := chain[len(chain)-1]
+	rv[RootCauseField] = fmt.Sprintf("%s (%T)", root, root)
+	return rv
+}
+
+// FError emits through lgr a message with fields describing the chain of
+// err as produced by ErrorFields.  Loggers that do not support fields
+// render them after the message per FF.  If err is nil the message is
+// emitted without fields.
+func FError(lgr ImmutableLogger, pri Priority, err error, format string, args ...interface{}) {
+	if !lgr.Priority().Enables(pri) {
+		return
+	}
+	FF(lgr, pri, ErrorFields(err), format, args...)
+}
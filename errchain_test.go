@@ -0,0 +1,64 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type opaqueError struct {
+	err error
+}
+
+func (e opaqueError) Error() string {
+	return "opaque failure"
+}
+
+func (e opaqueError) Unwrap() error {
+	return e.err
+}
+
+func TestErrorChain(t *testing.T) {
+	if ErrorChain(nil) != nil || ErrorLayers(nil) != nil || ErrorFields(nil) != nil {
+		t.Errorf("nil not handled")
+	}
+
+	if l := ErrorLayers(io.EOF); !reflect.DeepEqual(l, []string{"EOF"}) {
+		t.Errorf("wrong plain layers: %q", l)
+	}
+
+	err := fmt.Errorf("load config: %w", opaqueError{fmt.Errorf("read: %w", io.EOF)})
+	if c := ErrorChain(err); len(c) != 4 || c[3] != io.EOF {
+		t.Errorf("wrong chain: %v", c)
+	}
+	exp := []string{"load config", "opaque failure", "read", "EOF"}
+	if l := ErrorLayers(err); !reflect.DeepEqual(l, exp) {
+		t.Errorf("wrong layers: %q", l)
+	}
+	fields := ErrorFields(err)
+	if v := fields[RootCauseField]; v != "EOF (*errors.errorString)" {
+		t.Errorf("wrong root: %v", v)
+	}
+	if v := fields["cause1"]; v != "opaque failure" {
+		t.Errorf("wrong cause1: %v", v)
+	}
+}
+
+func TestFError(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	FError(lgr, Error, fmt.Errorf("ctx: %w", errors.New("bad")), "failed %d", 1)
+	FError(lgr, Error, nil, "no error")
+	exp := "[E] failed 1 cause0=ctx cause1=bad root=\"bad (*errors.errorString)\"\n" +
+		"[E] no error\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+}
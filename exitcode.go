@@ -0,0 +1,94 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync/atomic"
+)
+
+// Exit codes returned by SeverityLogger.ExitCode.
+const (
+	// ExitOK indicates nothing more severe than Notice was logged.
+	ExitOK = 0
+	// ExitWarning indicates the most severe message was a Warning.
+	ExitWarning = 1
+	// ExitError indicates an Error or more severe message was logged.
+	ExitError = 2
+)
+
+// SeverityLogger wraps a Logger to track the most severe priority of the
+// messages submitted to it, so a command-line tool can derive its exit
+// status from what it logged:
+//
+//	defer func() { os.Exit(lgr.ExitCode()) }()
+//
+// Messages are tracked whether or not the wrapped logger emits them, so
+// the exit status does not depend on verbosity.  All methods are safe for
+// concurrent use if the wrapped logger is.
+type SeverityLogger struct {
+	lgr   Logger
+	worst int32
+}
+
+// MakeSeverityLogger returns a SeverityLogger that forwards to lgr.
+func MakeSeverityLogger(lgr Logger) *SeverityLogger {
+	return &SeverityLogger{
+		lgr: lgr,
+	}
+}
+
+// WorstPriority returns the most severe priority of the messages submitted
+// so far, or an unset priority if there have been none.
+func (v *SeverityLogger) WorstPriority() Priority {
+	return Priority(atomic.LoadInt32(&v.worst))
+}
+
+// ExitCode returns ExitError if any message at Error or a more severe
+// priority has been submitted, ExitWarning if the most severe was a
+// Warning, and ExitOK otherwise.
+func (v *SeverityLogger) ExitCode() int {
+	switch pri := v.WorstPriority(); {
+	case !pri.IsSet() || pri > Warning:
+		return ExitOK
+	case pri == Warning:
+		return ExitWarning
+	}
+	return ExitError
+}
+
+// Priority per ImmutableLogger.
+func (v *SeverityLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *SeverityLogger) F(pri Priority, format string, args ...interface{}) {
+	if pri >= Emerg && pri <= Debug {
+		for {
+			worst := atomic.LoadInt32(&v.worst)
+			if (Priority(worst).IsSet() && worst <= int32(pri)) ||
+				atomic.CompareAndSwapInt32(&v.worst, worst, int32(pri)) {
+				break
+			}
+		}
+	}
+	v.lgr.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages do not affect the worst priority.
+func (v *SeverityLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *SeverityLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *SeverityLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,60 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSeverityLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeSeverityLogger(newTestLogger(&sb).SetPriority(Error))
+
+	if p := lgr.WorstPriority(); p.IsSet() {
+		t.Errorf("wrong initial worst: %s", p)
+	}
+	if c := lgr.ExitCode(); c != ExitOK {
+		t.Errorf("wrong initial code: %d", c)
+	}
+	lgr.F(Info, "info")
+	if c := lgr.ExitCode(); c != ExitOK {
+		t.Errorf("wrong info code: %d", c)
+	}
+	// Filtered messages are tracked.
+	lgr.F(Warning, "warning")
+	if c := lgr.ExitCode(); c != ExitWarning {
+		t.Errorf("wrong warning code: %d", c)
+	}
+	lgr.F(Crit, "crit")
+	lgr.F(Notice, "notice")
+	if p := lgr.WorstPriority(); p != Crit {
+		t.Errorf("wrong worst: %s", p)
+	}
+	if c := lgr.ExitCode(); c != ExitError {
+		t.Errorf("wrong error code: %d", c)
+	}
+	if s := sb.String(); s != "[C] crit\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestSeverityLoggerConcurrent(t *testing.T) {
+	lgr := MakeSeverityLogger(NullLogMaker(nil))
+	var wg sync.WaitGroup
+	for pri := Emerg; pri <= Debug; pri++ {
+		wg.Add(1)
+		go func(pri Priority) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				lgr.F(pri, "m")
+			}
+		}(pri)
+	}
+	wg.Wait()
+	if p := lgr.WorstPriority(); p != Emerg {
+		t.Errorf("wrong worst: %s", p)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// FailoverLogger emits each message to a primary logger and, only if
+// writing it there fails, to a fallback logger.  This keeps a durable record
+// when the primary sink, such as a network service, is temporarily
+// unavailable.
+//
+// Failover is decided per message: every message is first attempted on the
+// primary, and there is no sticky switch to the fallback after a failure.
+// Consequently the fallback holds exactly the messages that the primary
+// failed to write, in the order in which they failed.  Detecting failures
+// requires the primary to implement CheckedLogger, as LogLogger does; with
+// other primaries the fallback is never used.
+//
+// Messages are filtered by the priority of the primary before being
+// attempted, and by the fallback's own priority when failing over.
+// SetPriority and SetId are applied to both loggers.  All methods are safe
+// for concurrent use if both loggers are.
+type FailoverLogger struct {
+	primary   Logger
+	fallback  Logger
+	failovers uint64
+}
+
+// MakeFailoverLogger returns a FailoverLogger that emits to primary, falling
+// back to fallback.
+func MakeFailoverLogger(primary, fallback Logger) *FailoverLogger {
+	return &FailoverLogger{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Failovers returns the number of messages that the primary failed to
+// write.
+func (v *FailoverLogger) Failovers() uint64 {
+	return atomic.LoadUint64(&v.failovers)
+}
+
+// Priority per ImmutableLogger.  This is the priority of the primary.
+func (v *FailoverLogger) Priority() Priority {
+	return v.primary.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *FailoverLogger) F(pri Priority, format string, args ...interface{}) {
+	_ = v.FChecked(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are passed to the primary, since
+// Auditor provides no way to detect a failure.
+func (v *FailoverLogger) Audit(format string, args ...interface{}) {
+	Audit(v.primary, format, args...)
+}
+
+// FChecked per CheckedLogger.  The returned error is that from the
+// fallback, if the primary failed.
+func (v *FailoverLogger) FChecked(pri Priority, format string, args ...interface{}) error {
+	if !v.primary.Priority().Enables(pri) {
+		return nil
+	}
+	msg := fmt.Sprintf(format, args...)
+	if FChecked(v.primary, pri, "%s", msg) == nil {
+		return nil
+	}
+	atomic.AddUint64(&v.failovers, 1)
+	return FChecked(v.fallback, pri, "%s", msg)
+}
+
+// SetId per Logger.
+func (v *FailoverLogger) SetId(id string) Logger {
+	v.primary.SetId(id)
+	v.fallback.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *FailoverLogger) SetPriority(pri Priority) Logger {
+	v.primary.SetPriority(pri)
+	v.fallback.SetPriority(pri)
+	return v
+}
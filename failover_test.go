@@ -0,0 +1,70 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+// flakyWriter fails writes while fail is set.
+type flakyWriter struct {
+	sb   strings.Builder
+	fail bool
+}
+
+func (w *flakyWriter) Write(data []byte) (int, error) {
+	if w.fail {
+		return 0, errWriteFailed
+	}
+	return w.sb.Write(data)
+}
+
+func TestFChecked(t *testing.T) {
+	w := &flakyWriter{}
+	lgr := newTestLogger(new(strings.Builder)).(*LogLogger).SetOutput(w)
+	if err := FChecked(lgr, Info, "ok"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	w.fail = true
+	confirmError(t, FChecked(lgr, Info, "bad"), errWriteFailed, "write failed")
+	lgr.SetPriority(Warning)
+	if err := FChecked(lgr, Info, "filtered"); err != nil {
+		t.Errorf("filtered message failed: %v", err)
+	}
+	if err := FChecked(MakeOnceLogger(lgr, 0), Error, "unchecked"); err != nil {
+		t.Errorf("unchecked logger failed: %v", err)
+	}
+}
+
+func TestFailoverLogger(t *testing.T) {
+	w := &flakyWriter{}
+	var fsb strings.Builder
+	primary := newTestLogger(new(strings.Builder)).(*LogLogger).SetOutput(w)
+	lgr := MakeFailoverLogger(primary, newTestLogger(&fsb))
+	lgr.SetId("id ")
+
+	lgr.F(Info, "one")
+	w.fail = true
+	lgr.F(Info, "two %d", 2)
+	lgr.F(Error, "three")
+	w.fail = false
+	lgr.F(Info, "four")
+	lgr.SetPriority(Warning)
+	w.fail = true
+	lgr.F(Info, "filtered")
+
+	if s := w.sb.String(); s != "id [I] one\nid [I] four\n" {
+		t.Errorf("wrong primary: %q", s)
+	}
+	if s := fsb.String(); s != "id [I] two 2\nid [E] three\n" {
+		t.Errorf("wrong fallback: %q", s)
+	}
+	if n := lgr.Failovers(); n != 2 {
+		t.Errorf("wrong failovers: %d", n)
+	}
+
+	confirmError(t, MakeFailoverLogger(primary, primary).FChecked(Error, "both"),
+		errWriteFailed, "write failed")
+}
@@ -0,0 +1,123 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FormatFallbackArgs separates the format string from the arguments in a
+// message rendered by the format fallback of LogLogger.  The arguments
+// follow it, each rendered with %v and separated by ", ", e.g.:
+//
+//	copied %d of %d files; args: 3
+const FormatFallbackArgs = "; args: "
+
+// formatArgCount returns the number of arguments consumed by the verbs in
+// format.  ok is false if format uses explicit argument indexes, in which
+// case the count is not tracked.
+func formatArgCount(format string) (n int, ok bool) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				return 0, false
+			}
+			if c == '*' {
+				n++
+				continue
+			}
+			if strings.IndexByte("+-# 0.123456789", c) >= 0 {
+				continue
+			}
+			if c != '%' {
+				n++
+			}
+			break
+		}
+	}
+	return n, true
+}
+
+// fmtErrorMarker matches the markers fmt places in its output to report
+// errors, e.g. %!d(string=x) or %!(EXTRA int=3).
+var fmtErrorMarker = regexp.MustCompile(`%![a-zA-Z]?\(`)
+
+// formatVerbError determines whether fmt reports an error rendering any
+// verb of format with the arguments it consumes, e.g. an argument of the
+// wrong type.  Each verb is rendered separately so that text in an argument
+// that resembles an error marker is not mistaken for one.  format must not
+// use explicit argument indexes.
+func formatVerbError(format string, args []interface{}) bool {
+	ai := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		n := 0
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '*' {
+				n++
+				continue
+			}
+			if strings.IndexByte("+-# 0.123456789", c) >= 0 {
+				continue
+			}
+			if c == '%' {
+				break
+			}
+			n++
+			if ai+n > len(args) {
+				return true
+			}
+			r := fmt.Sprintf(format[start:i+1], args[ai:ai+n]...)
+			if strings.HasPrefix(r, "%!") && fmtErrorMarker.MatchString(r) {
+				return true
+			}
+			ai += n
+			break
+		}
+	}
+	return false
+}
+
+// formatMismatch determines whether args do not match format, either
+// because their number differs from what format consumes or because fmt
+// reported an error such as an argument of the wrong type in the rendered
+// message s.
+func formatMismatch(format string, args []interface{}, s string) bool {
+	n, ok := formatArgCount(format)
+	if ok && n != len(args) {
+		return true
+	}
+	if !strings.Contains(s, "%!") {
+		return false
+	}
+	if !ok {
+		// Argument use cannot be tracked; rely on the markers.
+		return fmtErrorMarker.MatchString(s)
+	}
+	return formatVerbError(format, args)
+}
+
+// formatFallback renders format unchanged followed by args.
+func formatFallback(format string, args []interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(format)
+	sb.WriteString(FormatFallbackArgs)
+	for i, a := range args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%v", a)
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatArgCount(t *testing.T) {
+	type testCase struct {
+		format string
+		n      int
+		ok     bool
+	}
+	testCases := []testCase{
+		{"", 0, true},
+		{"plain", 0, true},
+		{"100%%", 0, true},
+		{"%d of %s", 2, true},
+		{"%-8.3f|%+v|%#x", 3, true},
+		{"%*d %.*s", 4, true},
+		{"trailing %", 0, true},
+		{"%[2]d %[1]d", 0, false},
+	}
+	for _, tc := range testCases {
+		if n, ok := formatArgCount(tc.format); n != tc.n || ok != tc.ok {
+			t.Errorf("%q: got %d %t, expected %d %t", tc.format, n, ok, tc.n, tc.ok)
+		}
+	}
+}
+
+func TestLogLoggerFormatFallback(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	lgr.F(Info, "copied %d of %d files", 3)
+	if s := sb.String(); s != "[I] copied 3 of %!d(MISSING) files\n" {
+		t.Errorf("wrong default: %q", s)
+	}
+	sb.Reset()
+
+	ll.SetFormatFallback(true)
+	type testCase struct {
+		format string
+		args   []interface{}
+		exp    string
+	}
+	testCases := []testCase{
+		{"copied %d of %d files", []interface{}{3}, "copied %d of %d files; args: 3"},
+		{"copied %d files", []interface{}{3, "extra"}, "copied %d files; args: 3, extra"},
+		{"missing %s", nil, "missing %s; args: "},
+		{"copied %d of %d files", []interface{}{3, 4}, "copied 3 of 4 files"},
+		{"%d", []interface{}{"x"}, "%d; args: x"},
+		{"%d%% of %s", []interface{}{50, "http://h/%!d(x)"}, "50% of http://h/%!d(x)"},
+		{"%*d", []interface{}{"w", 3}, "%*d; args: w, 3"},
+		{"100%% of %*d", []interface{}{4, 7}, "100% of    7"},
+		{"%[2]s %[1]s", []interface{}{"a", "b"}, "b a"},
+		{"%[3]s", []interface{}{"a"}, "%[3]s; args: a"},
+		{"%[1]s", []interface{}{"100%!"}, "100%!"},
+	}
+	for _, tc := range testCases {
+		sb.Reset()
+		lgr.F(Info, tc.format, tc.args...)
+		if s := sb.String(); s != "[I] "+tc.exp+"\n" {
+			t.Errorf("%q: got %q, expected %q", tc.format, s, tc.exp)
+		}
+	}
+
+	// The argument limit applies to the fallback rendering.
+	sb.Reset()
+	ll.SetArgLimit(3)
+	lgr.F(Info, "%s %s", "abcdef")
+	if s := sb.String(); s != "[I] %s %s; args: abc"+ArgTruncated+"\n" {
+		t.Errorf("wrong limited fallback: %q", s)
+	}
+}
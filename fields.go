@@ -0,0 +1,336 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fields holds structured data associated with a message, keyed by field
+// name.
+type Fields map[string]interface{}
+
+// FieldLogger is implemented by loggers that can emit structured fields
+// along with a message.
+type FieldLogger interface {
+	ImmutableLogger
+
+	// FF formats a message and emits it to the log along with fields,
+	// subject to the same filtering as F.
+	FF(pri Priority, fields Fields, format string, args ...interface{})
+}
+
+// FF emits through lgr a message with associated fields.  If lgr does not
+// implement FieldLogger the fields are rendered after the message as
+// space-separated key=value text, in order of key, with the values of
+// fields with keys in DefaultSensitiveKeys replaced by MaskedValue.
+func FF(lgr ImmutableLogger, pri Priority, fields Fields, format string, args ...interface{}) {
+	if fl, ok := lgr.(FieldLogger); ok {
+		fl.FF(pri, fields, format, args...)
+	} else if lgr.Priority().Enables(pri) {
+		fields = defaultSensitiveKeys.mask(fields)
+		buf := appendFields([]byte(fmt.Sprintf(format, args...)), fields)
+		lgr.F(pri, "%s", buf)
+	}
+}
+
+// FMetric emits through lgr a message along with numeric measurements,
+// such as the number of items processed and the time taken, so one call
+// serves both people reading the log and tools extracting metrics.  The
+// metrics are passed to FF as fields with float64 values: loggers that
+// render fields as text, including LogLogger and loggers that do not
+// implement FieldLogger, append them to msg as key=value in order of key,
+// while structured sinks receive them as typed numeric fields.  msg is not
+// interpreted as a format string.
+func FMetric(lgr ImmutableLogger, pri Priority, msg string, metrics map[string]float64) {
+	if !lgr.Priority().Enables(pri) {
+		return
+	}
+	fields := make(Fields, len(metrics))
+	for k, v := range metrics {
+		fields[k] = v
+	}
+	FF(lgr, pri, fields, "%s", msg)
+}
+
+// MergeFields returns a new Fields holding the contents of base overlaid
+// with extra, along with the keys in extra that replaced a value in base in
+// sorted order.  Reporting the overridden keys allows development builds to
+// warn about accidental shadowing of base fields.  Neither argument is
+// modified, and either may be nil.
+func MergeFields(base, extra Fields) (Fields, []string) {
+	rv := make(Fields, len(base)+len(extra))
+	for k, v := range base {
+		rv[k] = v
+	}
+	var overridden []string
+	for k, v := range extra {
+		if _, ok := rv[k]; ok {
+			overridden = append(overridden, k)
+		}
+		rv[k] = v
+	}
+	sort.Strings(overridden)
+	return rv, overridden
+}
+
+// FieldPolicy lists, for each priority, the keys of the fields that are
+// retained in messages at that priority.  Priorities that are not present
+// retain all fields.  This allows keeping detailed context in Debug
+// messages while limiting higher priority messages to a few essential
+// fields.
+type FieldPolicy map[Priority][]string
+
+// fieldFilter is the lookup form of a FieldPolicy.
+type fieldFilter map[Priority]map[string]struct{}
+
+// makeFieldFilter converts policy to lookup form, returning nil if policy
+// is empty.
+func makeFieldFilter(policy FieldPolicy) fieldFilter {
+	if len(policy) == 0 {
+		return nil
+	}
+	rv := make(fieldFilter, len(policy))
+	for pri, keys := range policy {
+		allowed := make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			allowed[k] = struct{}{}
+		}
+		rv[pri] = allowed
+	}
+	return rv
+}
+
+// apply returns the subset of fields allowed at pri.  fields itself is
+// returned if no filtering is required.
+func (f fieldFilter) apply(pri Priority, fields Fields) Fields {
+	allowed, ok := f[pri]
+	if !ok {
+		return fields
+	}
+	rv := make(Fields, len(allowed))
+	for k, v := range fields {
+		if _, ok := allowed[k]; ok {
+			rv[k] = v
+		}
+	}
+	return rv
+}
+
+// MaskedValue replaces the value of a sensitive field when it is rendered.
+const MaskedValue = "***"
+
+// DefaultSensitiveKeys lists the keys of fields whose values LogLogger masks
+// unless changed with SetSensitiveKeys.  The other FieldLogger
+// implementations in this package, and FF when rendering fields for a
+// logger that does not implement FieldLogger, always mask these keys.
+var DefaultSensitiveKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"api_key",
+	"apikey",
+	"cookie",
+}
+
+// sensitiveKeys holds the lower-case keys of fields to be masked.
+type sensitiveKeys map[string]struct{}
+
+var defaultSensitiveKeys = makeSensitiveKeys(DefaultSensitiveKeys)
+
+func makeSensitiveKeys(keys []string) sensitiveKeys {
+	rv := make(sensitiveKeys, len(keys))
+	for _, k := range keys {
+		rv[strings.ToLower(k)] = struct{}{}
+	}
+	return rv
+}
+
+// mask returns fields with the values of sensitive fields replaced by
+// MaskedValue, matching keys without regard to case.  fields itself is
+// returned if nothing needs to be masked.
+func (s sensitiveKeys) mask(fields Fields) Fields {
+	if len(s) == 0 {
+		return fields
+	}
+	var rv Fields
+	for k := range fields {
+		if _, ok := s[strings.ToLower(k)]; ok {
+			if rv == nil {
+				rv = make(Fields, len(fields))
+				for k2, v := range fields {
+					rv[k2] = v
+				}
+			}
+			rv[k] = MaskedValue
+		}
+	}
+	if rv == nil {
+		return fields
+	}
+	return rv
+}
+
+// DefaultFieldSeparator precedes each field rendered as key=value text
+// unless a logger specifies otherwise.
+const DefaultFieldSeparator = " "
+
+// FieldQuoting selects how field values rendered as key=value text are
+// protected when they cannot be written as-is.
+type FieldQuoting int
+
+const (
+	// QuoteAsNeeded renders values within double quotes using Go escape
+	// sequences if they are empty or contain the separator, spaces,
+	// quotes, equals signs, or non-printable characters.  This is
+	// compatible with logfmt parsers, and is the default.
+	QuoteAsNeeded FieldQuoting = iota
+	// QuoteAlways renders every value within double quotes using Go
+	// escape sequences.
+	QuoteAlways
+	// EscapeBackslash never quotes values.  Instead backslashes, newlines,
+	// carriage returns, and tabs are rendered as \\, \n, \r, and \t, and
+	// any other occurrence of the separator is preceded by a backslash.
+	// This suits parsers that split on the separator and do not
+	// understand quotes.
+	EscapeBackslash
+)
+
+// fieldFormat holds the options used to render fields as key=value text.
+// The zero value uses DefaultFieldSeparator and QuoteAsNeeded.
+type fieldFormat struct {
+	sep   string
+	quote FieldQuoting
+}
+
+// appendFields appends to buf each field as the separator followed by
+// key=value, in order of key.  Values are rendered with fmt.Sprint and
+// protected according to the quoting style.
+func (f fieldFormat) appendFields(buf []byte, fields Fields) []byte {
+	sep := f.sep
+	if sep == "" {
+		sep = DefaultFieldSeparator
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf = append(buf, sep...)
+		buf = append(buf, k...)
+		buf = append(buf, '=')
+		buf = appendFieldValue(buf, fmt.Sprint(fields[k]), sep, f.quote)
+	}
+	return buf
+}
+
+// appendFields appends fields to buf using the default format.
+func appendFields(buf []byte, fields Fields) []byte {
+	return fieldFormat{}.appendFields(buf, fields)
+}
+
+// fieldEscapes holds the escape sequences used by EscapeBackslash.
+var fieldEscapes = map[byte]string{
+	'\\': `\\`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+}
+
+// appendFieldValue appends s to buf, protected per quote so that it can be
+// distinguished from the separator sep.
+func appendFieldValue(buf []byte, s string, sep string, quote FieldQuoting) []byte {
+	switch quote {
+	case QuoteAlways:
+		return strconv.AppendQuote(buf, s)
+	case EscapeBackslash:
+		for len(s) > 0 {
+			if esc, ok := fieldEscapes[s[0]]; ok {
+				buf = append(buf, esc...)
+				s = s[1:]
+			} else if strings.HasPrefix(s, sep) {
+				buf = append(buf, '\\')
+				buf = append(buf, sep...)
+				s = s[len(sep):]
+			} else {
+				buf = append(buf, s[0])
+				s = s[1:]
+			}
+		}
+		return buf
+	}
+	needQuote := s == "" || strings.Contains(s, sep)
+	for _, r := range s {
+		if needQuote {
+			break
+		}
+		needQuote = r == ' ' || r == '"' || r == '=' || !strconv.IsPrint(r)
+	}
+	if needQuote {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// MissingField is rendered by FTemplate in place of a placeholder for which
+// there is no field, following the field name.
+const MissingField = ":MISSING"
+
+// FTemplate emits through lgr a message produced from a template with named
+// placeholders, in the style of message-template logging conventions.  Each
+// placeholder "{name}" in template is replaced by the value of the field
+// with that name, rendered with fmt.Sprint; a placeholder for which there is
+// no field is rendered as "{name:MISSING}".  Use "{{" and "}}" for literal
+// braces.
+//
+// All fields are passed along with the message through FF, so structured
+// loggers retain them.  Nothing is rendered if lgr would not emit the
+// message.
+func FTemplate(lgr ImmutableLogger, pri Priority, template string, fields Fields) {
+	if !lgr.Priority().Enables(pri) {
+		return
+	}
+	FF(lgr, pri, fields, "%s", expandTemplate(template, fields))
+}
+
+// expandTemplate substitutes fields into template per FTemplate.
+func expandTemplate(template string, fields Fields) string {
+	var sb strings.Builder
+	for len(template) > 0 {
+		i := strings.IndexAny(template, "{}")
+		if i < 0 {
+			sb.WriteString(template)
+			break
+		}
+		sb.WriteString(template[:i])
+		c := template[i]
+		template = template[i+1:]
+		if len(template) > 0 && template[0] == c {
+			// Escaped brace.
+			sb.WriteByte(c)
+			template = template[1:]
+			continue
+		}
+		end := strings.IndexByte(template, '}')
+		if c == '}' || end < 0 {
+			// Unbalanced brace is rendered as-is.
+			sb.WriteByte(c)
+			continue
+		}
+		name := template[:end]
+		template = template[end+1:]
+		if v, ok := fields[name]; ok {
+			fmt.Fprint(&sb, v)
+		} else {
+			sb.WriteString("{" + name + MissingField + "}")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,247 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFF(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	FF(lgr, Info, Fields{
+		"user":  "bob",
+		"count": 3,
+		"note":  "a \"b\" c",
+		"empty": "",
+	}, "processed %s", "batch")
+	if s := sb.String(); s != "[I] processed batch count=3 empty=\"\" note=\"a \\\"b\\\" c\" user=bob\n" {
+		t.Errorf("wrong FF: %q", s)
+	}
+	sb.Reset()
+
+	// Loggers that do not implement FieldLogger get the same rendering.
+	olgr := MakeOnceLogger(lgr, 10)
+	FF(olgr, Info, Fields{"k": "v=1"}, "msg")
+	lgr.SetPriority(Warning)
+	FF(olgr, Info, Fields{"k": "v"}, "filtered")
+	FF(lgr, Info, Fields{"k": "v"}, "filtered")
+	if s := sb.String(); s != "[I] msg k=\"v=1\"\n" {
+		t.Errorf("wrong fallback FF: %q", s)
+	}
+}
+
+func TestFMetric(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	metrics := map[string]float64{
+		"items":   42,
+		"seconds": 1.5,
+		"errors":  0,
+	}
+
+	FMetric(lgr, Info, "processed 100% of batch", metrics)
+	FMetric(MakeOnceLogger(lgr, 10), Info, "fallback", metrics)
+	if s := sb.String(); s != "[I] processed 100% of batch errors=0 items=42 seconds=1.5\n"+
+		"[I] fallback errors=0 items=42 seconds=1.5\n" {
+		t.Errorf("wrong FMetric: %q", s)
+	}
+
+	var recs []Record
+	rlgr := RecordLogMaker(func(r Record) {
+		recs = append(recs, r)
+	})(nil)
+	FMetric(rlgr, Info, "filtered", metrics)
+	FMetric(rlgr, Warning, "structured", metrics)
+	if len(recs) != 1 {
+		t.Fatalf("wrong record count: %d", len(recs))
+	}
+	if r := recs[0]; r.Message != "structured" || len(r.Fields) != 3 {
+		t.Errorf("wrong record: %+v", r)
+	} else if v, ok := r.Fields["seconds"].(float64); !ok || v != 1.5 {
+		t.Errorf("wrong metric field: %#v", r.Fields["seconds"])
+	}
+}
+
+func TestFTemplate(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	FTemplate(lgr, Notice, "user {user} did {action} {{literal}} {missing}", Fields{
+		"user":   "bob",
+		"action": "login",
+	})
+	if s := sb.String(); s != "[N] user bob did login {literal} {missing:MISSING} action=login user=bob\n" {
+		t.Errorf("wrong template: %q", s)
+	}
+
+	type testCase struct {
+		tmpl string
+		exp  string
+	}
+	testCases := []testCase{
+		{"", ""},
+		{"plain", "plain"},
+		{"{n}", "1"},
+		{"a}b", "a}b"},
+		{"a{b", "a{b"},
+		{"}}{{", "}{"},
+		{"{n}{n}", "11"},
+	}
+	for _, tc := range testCases {
+		if s := expandTemplate(tc.tmpl, Fields{"n": 1}); s != tc.exp {
+			t.Errorf("%q expanded to %q not %q", tc.tmpl, s, tc.exp)
+		}
+	}
+
+	sb.Reset()
+	lgr.SetPriority(Warning)
+	FTemplate(lgr, Notice, "{x}", nil)
+	if s := sb.String(); s != "" {
+		t.Errorf("filtered template emitted: %q", s)
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	base := Fields{"a": 1, "b": 2, "c": 3}
+	extra := Fields{"c": 30, "d": 4, "a": 10}
+	m, over := MergeFields(base, extra)
+	if !reflect.DeepEqual(m, Fields{"a": 10, "b": 2, "c": 30, "d": 4}) {
+		t.Errorf("wrong merge: %v", m)
+	}
+	if !reflect.DeepEqual(over, []string{"a", "c"}) {
+		t.Errorf("wrong overrides: %v", over)
+	}
+	if len(base) != 3 || base["a"] != 1 {
+		t.Errorf("base modified: %v", base)
+	}
+
+	m, over = MergeFields(nil, nil)
+	if m == nil || len(m) != 0 || over != nil {
+		t.Errorf("wrong empty merge: %v %v", m, over)
+	}
+}
+
+func TestFieldPolicy(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lgr.(*LogLogger).SetFieldPolicy(FieldPolicy{
+		Info:  {"user"},
+		Error: {"user", "code"},
+	})
+	fields := Fields{"user": "bob", "code": 7, "trace": "abc"}
+	FF(lgr, Debug, fields, "debug")
+	FF(lgr, Info, fields, "info")
+	FF(lgr, Error, fields, "error")
+	FF(lgr, Warning, Fields{"trace": 1}, "none")
+	exp := "[D] debug code=7 trace=abc user=bob\n" +
+		"[I] info user=bob\n" +
+		"[E] error code=7 user=bob\n" +
+		"[W] none trace=1\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	if len(fields) != 3 {
+		t.Errorf("fields modified: %v", fields)
+	}
+
+	sb.Reset()
+	lgr.(*LogLogger).SetFieldPolicy(nil)
+	FF(lgr, Info, fields, "info")
+	if s := sb.String(); s != "[I] info code=7 trace=abc user=bob\n" {
+		t.Errorf("wrong restored output: %q", s)
+	}
+}
+
+func TestSensitiveKeys(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	fields := Fields{"user": "bob", "Password": "hunter2", "AUTHORIZATION": "Bearer x"}
+	FF(lgr, Info, fields, "default")
+	ll.SetSensitiveKeys("USER")
+	FF(lgr, Info, fields, "custom")
+	ll.SetSensitiveKeys()
+	FF(lgr, Info, fields, "none")
+
+	exp := "[I] default AUTHORIZATION=*** Password=*** user=bob\n" +
+		"[I] custom AUTHORIZATION=\"Bearer x\" Password=hunter2 user=***\n" +
+		"[I] none AUTHORIZATION=\"Bearer x\" Password=hunter2 user=bob\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	if fields["Password"] != "hunter2" {
+		t.Errorf("fields modified: %v", fields)
+	}
+
+	// Fields rendered for loggers that do not implement FieldLogger are
+	// masked.
+	sb.Reset()
+	FF(MakeMaxLineLogger(lgr, 80), Info, Fields{"password": "hunter2"}, "decorated")
+	clgr, ch := MakeChanLogger(lgr, 1)
+	FF(clgr, Info, Fields{"password": "hunter2"}, "queued")
+	(<-ch).Emit()
+	exp = "[I] decorated password=***\n[I] queued password=***\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong wrapped output: %q", s)
+	}
+}
+
+func TestFieldFormat(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+	fields := Fields{
+		"a": "x\ty",
+		"b": "say \"hi\"",
+		"c": "l1\nl2",
+		"d": `c:\dir`,
+		"e": "plain",
+	}
+
+	type testCase struct {
+		sep   string
+		quote FieldQuoting
+		exp   string
+	}
+	tests := []testCase{
+		{"", QuoteAsNeeded,
+			`m a="x\ty" b="say \"hi\"" c="l1\nl2" d=c:\dir e=plain`},
+		{"\t", QuoteAsNeeded,
+			"m\ta=\"x\\ty\"\tb=\"say \\\"hi\\\"\"\tc=\"l1\\nl2\"\td=c:\\dir\te=plain"},
+		{"", QuoteAlways,
+			`m a="x\ty" b="say \"hi\"" c="l1\nl2" d="c:\\dir" e="plain"`},
+		{"", EscapeBackslash,
+			`m a=x\ty b=say\ "hi" c=l1\nl2 d=c:\\dir e=plain`},
+		{"\t", EscapeBackslash,
+			"m\ta=x\\ty\tb=say \"hi\"\tc=l1\\nl2\td=c:\\\\dir\te=plain"},
+		{" | ", EscapeBackslash,
+			`m | a=x\ty | b=say "hi" | c=l1\nl2 | d=c:\\dir | e=plain`},
+	}
+	for _, tc := range tests {
+		ll.SetFieldSeparator(tc.sep).SetFieldQuoting(tc.quote)
+		sb.Reset()
+		FF(lgr, Info, fields, "m")
+		if s := sb.String(); s != "[I] "+tc.exp+"\n" {
+			t.Errorf("%q %d:\n got %s\nwant %s", tc.sep, tc.quote, s, tc.exp)
+		}
+	}
+
+	ll.SetFieldSeparator(" | ").SetFieldQuoting(EscapeBackslash)
+	sb.Reset()
+	FF(lgr, Info, Fields{"k": "a | b"}, "m")
+	if s := sb.String(); s != "[I] m | k=a\\ | b\n" {
+		t.Errorf("separator not escaped: %q", s)
+	}
+	ll.SetFieldQuoting(QuoteAsNeeded)
+	sb.Reset()
+	FF(lgr, Info, Fields{"k": "a|b", "j": "a | b"}, "m")
+	if s := sb.String(); s != "[I] m | j=\"a | b\" | k=a|b\n" {
+		t.Errorf("separator not quoted: %q", s)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+)
+
+// keyedLogger is implemented by loggers that accept a deduplication key
+// with each message, such as KeyedOnceLogger.
+type keyedLogger interface {
+	FKey(key string, pri Priority, format string, args ...interface{})
+}
+
+// FirehoseLogger emits each message to two loggers with different
+// policies: a primary logger intended for human operators, and a firehose
+// logger that receives the complete stream for archival.  This keeps a
+// clean operator-facing log and a complete archive from a single call site.
+//
+// Deduplication and rate limiting apply to the primary only.  The primary
+// is normally a chain of wrappers such as OnceLogger, KeyedOnceLogger, or
+// RateLimitLogger around the operator's sink, and whatever those wrappers
+// suppress still reaches the firehose.  The firehose is filtered only by
+// its own priority, so to archive everything it should be set to Debug.
+//
+// SetId is applied to both loggers.  SetPriority is applied only to the
+// primary, so that adjusting operator verbosity does not reduce what is
+// archived.  All methods are safe for concurrent use if both loggers are.
+type FirehoseLogger struct {
+	primary  Logger
+	firehose Logger
+}
+
+// MakeFirehoseLogger returns a FirehoseLogger that emits every message to
+// firehose and applies the policies of primary to the operator log.
+func MakeFirehoseLogger(primary, firehose Logger) *FirehoseLogger {
+	return &FirehoseLogger{
+		primary:  primary,
+		firehose: firehose,
+	}
+}
+
+// Firehose returns the logger that receives the complete stream.
+func (v *FirehoseLogger) Firehose() Logger {
+	return v.firehose
+}
+
+// Priority per ImmutableLogger.  This is the least restrictive of the
+// priorities of the primary and the firehose.
+func (v *FirehoseLogger) Priority() Priority {
+	pri := v.primary.Priority()
+	if p := v.firehose.Priority(); p > pri {
+		pri = p
+	}
+	return pri
+}
+
+// F per ImmutableLogger.  The message is formatted at most once.
+func (v *FirehoseLogger) F(pri Priority, format string, args ...interface{}) {
+	v.FKey("", pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are passed to both the primary and
+// the firehose.
+func (v *FirehoseLogger) Audit(format string, args ...interface{}) {
+	s := fmt.Sprintf(format, args...)
+	Audit(v.firehose, "%s", s)
+	Audit(v.primary, "%s", s)
+}
+
+// FKey emits a message to the firehose, and to the primary with key as
+// the deduplication key if the primary accepts one, as KeyedOnceLogger
+// does.  An empty key, or a primary that does not accept keys, submits the
+// message to the primary through F.
+func (v *FirehoseLogger) FKey(key string, pri Priority, format string, args ...interface{}) {
+	toPrimary := v.primary.Priority().Enables(pri)
+	toFirehose := v.firehose.Priority().Enables(pri)
+	if !(toPrimary || toFirehose) {
+		return
+	}
+	s := fmt.Sprintf(format, args...)
+	if toFirehose {
+		v.firehose.F(pri, "%s", s)
+	}
+	if toPrimary {
+		if kl, ok := v.primary.(keyedLogger); ok && key != "" {
+			kl.FKey(key, pri, "%s", s)
+		} else {
+			v.primary.F(pri, "%s", s)
+		}
+	}
+}
+
+// SetId per Logger.
+func (v *FirehoseLogger) SetId(id string) Logger {
+	v.primary.SetId(id)
+	v.firehose.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.  Only the priority of the primary is changed.
+func (v *FirehoseLogger) SetPriority(pri Priority) Logger {
+	v.primary.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,61 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFirehoseLogger(t *testing.T) {
+	var psb, fsb strings.Builder
+	primary := MakeOnceLogger(newTestLogger(&psb), 8)
+	lgr := MakeFirehoseLogger(primary, newTestLogger(&fsb))
+
+	for i := 0; i < 3; i++ {
+		lgr.F(Warning, "disk %d%% full", 90)
+	}
+	if s := psb.String(); s != "[W] disk 90% full\n" {
+		t.Errorf("wrong primary: %q", s)
+	}
+	if n := strings.Count(fsb.String(), "\n"); n != 3 {
+		t.Errorf("wrong firehose: %q", fsb.String())
+	}
+	psb.Reset()
+	fsb.Reset()
+
+	// Priority changes affect only the primary.
+	lgr.SetPriority(Warning)
+	if p := lgr.Priority(); p != Debug {
+		t.Errorf("wrong priority: %s", p)
+	}
+	lgr.F(Info, "info")
+	if psb.Len() != 0 || fsb.String() != "[I] info\n" {
+		t.Errorf("wrong filter: %q %q", psb.String(), fsb.String())
+	}
+	psb.Reset()
+	fsb.Reset()
+
+	lgr.SetId("id")
+	lgr.F(Error, "err")
+	if psb.String() != "id[E] err\n" || fsb.String() != "id[E] err\n" {
+		t.Errorf("wrong id: %q %q", psb.String(), fsb.String())
+	}
+}
+
+func TestFirehoseLoggerFKey(t *testing.T) {
+	var psb, fsb strings.Builder
+	primary := MakeKeyedOnceLogger(newTestLogger(&psb), 0, 8)
+	lgr := MakeFirehoseLogger(primary, newTestLogger(&fsb))
+
+	for i := 0; i < 3; i++ {
+		lgr.FKey("conn", Warning, "retry %d", i)
+	}
+	if s := psb.String(); s != "[W] retry 0\n" {
+		t.Errorf("wrong primary: %q", s)
+	}
+	if s := fsb.String(); s != "[W] retry 0\n[W] retry 1\n[W] retry 2\n" {
+		t.Errorf("wrong firehose: %q", s)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// GELFVersion is the version of the Graylog Extended Log Format produced by
+// GELFLogMaker loggers.
+const GELFVersion = "1.1"
+
+// GELFIdField is the additional field that holds the identifier assigned
+// with SetId to GELFLogMaker loggers.
+const GELFIdField = "_logger"
+
+// GELFChunkSize is the largest datagram sent by GELFLogMaker loggers.
+// Payloads that do not fit in one datagram are split into chunks of at most
+// this size, including the chunk header.
+const GELFChunkSize = 1420
+
+// gelfMaxChunks is the largest number of chunks allowed for one message.
+const gelfMaxChunks = 128
+
+// gelfChunkHeaderLen is the length of the header on each chunk: two magic
+// bytes, an eight-byte message identifier, the sequence number, and the
+// sequence count.
+const gelfChunkHeaderLen = 12
+
+// GELFLogMaker returns a LogMaker that creates loggers that send messages
+// to a Graylog server at addr, e.g. "graylog:12201", as uncompressed GELF
+// payloads over UDP, along with a Closer that releases the connection when
+// the loggers are no longer used.  An error is returned if addr cannot be
+// resolved.
+//
+// Each payload has version per GELFVersion, host holding the local host
+// name, short_message holding the formatted message, timestamp in seconds
+// with millisecond resolution, and level holding the syslog level of the
+// message priority.  The identifier, if any, is placed in GELFIdField.
+// Created loggers implement FieldLogger; fields are added as additional
+// fields with an underscore prepended to the key.  Numeric values are
+// sent as numbers and all others as their default format.  A field named
+// "id" is dropped, since GELF does not allow an additional field named
+// _id.  Values of fields with keys in DefaultSensitiveKeys are replaced by
+// MaskedValue.  If the fields cannot be encoded, e.g. because a value is a
+// NaN, the message is sent without them.
+//
+// Payloads larger than GELFChunkSize are sent as chunked GELF; payloads
+// that need more than 128 chunks are dropped.  Errors sending a message are
+// not reported: the message is dropped and the connection is
+// re-established for the next message.  Created loggers have priority
+// Warning.
+func GELFLogMaker(addr string) (LogMaker, io.Closer, error) {
+	if _, err := net.ResolveUDPAddr("udp", addr); err != nil {
+		return nil, nil, err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	snd := &gelfSender{
+		addr: addr,
+	}
+	mk := func(interface{}) Logger {
+		return &gelfLogger{
+			snd:  snd,
+			host: host,
+			pri:  Warning,
+		}
+	}
+	return mk, snd, nil
+}
+
+// gelfSender transmits GELF payloads, reconnecting after errors.  All
+// methods are safe for concurrent use.
+type gelfSender struct {
+	addr   string
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// Close per io.Closer.  Messages submitted after Close are dropped.
+func (s *gelfSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// send transmits payload, chunking it if necessary.
+func (s *gelfSender) send(payload []byte) {
+	var dgrams [][]byte
+	if len(payload) <= GELFChunkSize {
+		dgrams = [][]byte{payload}
+	} else {
+		dgrams = gelfChunks(payload)
+		if dgrams == nil {
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.conn == nil {
+		conn, err := net.Dial("udp", s.addr)
+		if err != nil {
+			return
+		}
+		s.conn = conn
+	}
+	for _, d := range dgrams {
+		if _, err := s.conn.Write(d); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return
+		}
+	}
+}
+
+// gelfChunks splits payload into chunked GELF datagrams, returning nil if
+// it requires too many chunks.
+func gelfChunks(payload []byte) [][]byte {
+	const room = GELFChunkSize - gelfChunkHeaderLen
+	n := (len(payload) + room - 1) / room
+	if n > gelfMaxChunks {
+		return nil
+	}
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	rv := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		part := payload[i*room:]
+		if len(part) > room {
+			part = part[:room]
+		}
+		d := make([]byte, 0, gelfChunkHeaderLen+len(part))
+		d = append(d, 0x1e, 0x0f)
+		d = append(d, id[:]...)
+		d = append(d, byte(i), byte(n))
+		rv = append(rv, append(d, part...))
+	}
+	return rv
+}
+
+// gelfValue returns fv in a form that GELF accepts for an additional
+// field: a number or a string.
+func gelfValue(fv interface{}) interface{} {
+	if sv, ok := fv.(structuredValue); ok {
+		return sv.structured()
+	}
+	switch fv.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return fv
+	case string:
+		return fv
+	}
+	return fmt.Sprint(fv)
+}
+
+// gelfLogger emits messages as GELF payloads.  All methods are safe for
+// concurrent use.
+type gelfLogger struct {
+	snd  *gelfSender
+	host string
+	mu   sync.Mutex
+	id   string
+	pri  Priority
+}
+
+// Priority per ImmutableLogger.
+func (v *gelfLogger) Priority() Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pri
+}
+
+// F per ImmutableLogger.
+func (v *gelfLogger) F(pri Priority, format string, args ...interface{}) {
+	v.FF(pri, nil, format, args...)
+}
+
+// FF per FieldLogger.
+func (v *gelfLogger) FF(pri Priority, fields Fields, format string, args ...interface{}) {
+	v.mu.Lock()
+	id, enabled := v.id, v.pri.Enables(pri)
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	fields = defaultSensitiveKeys.mask(fields)
+	base := map[string]interface{}{
+		"version":       GELFVersion,
+		"host":          v.host,
+		"short_message": fmt.Sprintf(format, args...),
+		"timestamp":     float64(time.Now().UnixNano()/int64(time.Millisecond)) / 1e3,
+		"level":         pri.Level(),
+	}
+	if id != "" {
+		base[GELFIdField] = id
+	}
+	obj := make(map[string]interface{}, len(fields)+len(base))
+	for k, fv := range fields {
+		if k != "id" {
+			obj["_"+k] = gelfValue(fv)
+		}
+	}
+	for k, bv := range base {
+		obj[k] = bv
+	}
+	buf, err := json.Marshal(obj)
+	if err != nil {
+		// A field value could not be encoded; send without fields.
+		if buf, err = json.Marshal(base); err != nil {
+			return
+		}
+	}
+	v.snd.send(buf)
+}
+
+// SetId per Logger.
+func (v *gelfLogger) SetId(id string) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.id = id
+	return v
+}
+
+// SetPriority per Logger.
+func (v *gelfLogger) SetPriority(pri Priority) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
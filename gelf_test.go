@@ -0,0 +1,125 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFLogMaker(t *testing.T) {
+	if _, _, err := GELFLogMaker("bad address"); err == nil {
+		t.Errorf("bad address not detected")
+	}
+
+	srv, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer srv.Close()
+	mk, closer, err := GELFLogMaker(srv.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("maker: %v", err)
+	}
+	defer closer.Close()
+
+	read := func() []byte {
+		t.Helper()
+		buf := make([]byte, 2*GELFChunkSize)
+		_ = srv.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := srv.Read(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return buf[:n]
+	}
+
+	lgr := mk(nil)
+	lgr.SetId("svc")
+	lgr.F(Info, "dropped")
+	t0 := float64(time.Now().Unix())
+	FF(lgr, Error, Fields{"user": "bob", "n": 3, "d": time.Second, "id": 7, "token": "t0k"}, "failed %d", 3)
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(read(), &obj); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if ts, ok := obj["timestamp"].(float64); !ok || ts < t0 || ts > t0+5 {
+		t.Errorf("bad timestamp: %v", obj["timestamp"])
+	}
+	delete(obj, "timestamp")
+	if h, ok := obj["host"].(string); !ok || h == "" {
+		t.Errorf("bad host: %v", obj["host"])
+	}
+	delete(obj, "host")
+	exp := map[string]interface{}{
+		"version":       "1.1",
+		"short_message": "failed 3",
+		"level":         3.0,
+		"_logger":       "svc",
+		"_user":         "bob",
+		"_n":            3.0,
+		"_d":            "1s",
+		"_token":        MaskedValue,
+	}
+	if len(obj) != len(exp) {
+		t.Errorf("wrong fields: %v", obj)
+	}
+	for k, v := range exp {
+		if obj[k] != v {
+			t.Errorf("%s: got %v, expected %v", k, obj[k], v)
+		}
+	}
+
+	// Fields that cannot be encoded are omitted.
+	FMetric(lgr, Error, "bad metric", map[string]float64{"ratio": math.NaN()})
+	obj = nil
+	if err := json.Unmarshal(read(), &obj); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if obj["short_message"] != "bad metric" || obj["_logger"] != "svc" {
+		t.Errorf("wrong unencodable message: %v", obj)
+	}
+	if _, ok := obj["_ratio"]; ok {
+		t.Errorf("unencodable field sent: %v", obj)
+	}
+
+	// A large message is chunked.
+	msg := strings.Repeat("x", 3*GELFChunkSize)
+	lgr.F(Warning, "%s", msg)
+	var payload []byte
+	var id []byte
+	for i := 0; i < 4; i++ {
+		d := read()
+		if len(d) > GELFChunkSize {
+			t.Fatalf("chunk too large: %d", len(d))
+		}
+		if d[0] != 0x1e || d[1] != 0x0f || d[10] != byte(i) || d[11] != 4 {
+			t.Fatalf("bad chunk header: % x", d[:12])
+		}
+		if id == nil {
+			id = d[2:10]
+		} else if !bytes.Equal(id, d[2:10]) {
+			t.Errorf("message id changed")
+		}
+		payload = append(payload, d[12:]...)
+	}
+	obj = nil
+	if err := json.Unmarshal(payload, &obj); err != nil {
+		t.Fatalf("unmarshal chunked: %v", err)
+	}
+	if obj["short_message"] != msg {
+		t.Errorf("wrong chunked message")
+	}
+
+	// Oversized messages are dropped.
+	if c := gelfChunks(make([]byte, gelfMaxChunks*GELFChunkSize)); c != nil {
+		t.Errorf("oversized payload chunked")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"runtime/debug"
+)
+
+// Guard runs fn, and if fn panics logs the panic value and the stack of the
+// panicking goroutine through lgr at pri.  This ensures a goroutine that
+// dies from a panic leaves a record in the application log, e.g.:
+//
+//	go lw.Guard(lgr, lw.Crit, true, worker)
+//
+// If repanic is true the panic is resumed after it is logged, so the
+// program still terminates as it would have without Guard.  Otherwise the
+// panic is absorbed and the recovered value is returned; the return value
+// is nil if fn did not panic.  The message is subject to the filtering of
+// lgr like any other.
+func Guard(lgr ImmutableLogger, pri Priority, repanic bool, fn func()) (recovered interface{}) {
+	defer func() {
+		if recovered = recover(); recovered != nil {
+			lgr.F(pri, "panic: %v\n%s", recovered, debug.Stack())
+			if repanic {
+				panic(recovered)
+			}
+		}
+	}()
+	fn()
+	return
+}
@@ -0,0 +1,43 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	ran := false
+	if r := Guard(lgr, Crit, false, func() { ran = true }); r != nil || !ran {
+		t.Errorf("wrong normal run: %v %v", r, ran)
+	}
+	if s := sb.String(); s != "" {
+		t.Errorf("unexpected output: %q", s)
+	}
+
+	if r := Guard(lgr, Crit, false, func() { panic("boom") }); r != "boom" {
+		t.Errorf("wrong recovered value: %v", r)
+	}
+	s := sb.String()
+	if !strings.HasPrefix(s, "[C] panic: boom\ngoroutine ") || !strings.Contains(s, "TestGuard") {
+		t.Errorf("wrong output: %q", s)
+	}
+	sb.Reset()
+
+	func() {
+		defer func() {
+			if r := recover(); r != "again" {
+				t.Errorf("wrong repanic: %v", r)
+			}
+		}()
+		Guard(lgr, Error, true, func() { panic("again") })
+	}()
+	if s := sb.String(); !strings.HasPrefix(s, "[E] panic: again\n") {
+		t.Errorf("wrong repanic output: %q", s)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync/atomic"
+)
+
+// LoggerHandle is a Logger that delegates to a target Logger which can be
+// replaced at any time.  Holders of the handle see the replacement on their
+// next call without having to fetch a new logger, which supports switching
+// log infrastructure when an application reloads its configuration.
+//
+// All LoggerHandle methods are safe for concurrent use, but each call is
+// delegated to the target in effect when it is made.  The target itself
+// must be safe for concurrent use if the handle is shared among goroutines.
+type LoggerHandle struct {
+	tgt atomic.Value
+}
+
+// loggerBox allows atomic.Value to hold Logger values of different concrete
+// types.
+type loggerBox struct {
+	lgr Logger
+}
+
+// MakeLoggerHandle returns a LoggerHandle that delegates to lgr.
+func MakeLoggerHandle(lgr Logger) *LoggerHandle {
+	rv := &LoggerHandle{}
+	rv.tgt.Store(loggerBox{lgr})
+	return rv
+}
+
+// Target returns the Logger to which the handle currently delegates.
+func (v *LoggerHandle) Target() Logger {
+	return v.tgt.Load().(loggerBox).lgr
+}
+
+// Swap replaces the target of the handle with lgr, and returns the previous
+// target.  The new target's identifier and priority are not changed.
+func (v *LoggerHandle) Swap(lgr Logger) Logger {
+	return v.tgt.Swap(loggerBox{lgr}).(loggerBox).lgr
+}
+
+// Priority per ImmutableLogger.
+func (v *LoggerHandle) Priority() Priority {
+	return v.Target().Priority()
+}
+
+// F per ImmutableLogger.
+func (v *LoggerHandle) F(pri Priority, format string, args ...interface{}) {
+	v.Target().F(pri, format, args...)
+}
+
+// Audit per Auditor.
+func (v *LoggerHandle) Audit(format string, args ...interface{}) {
+	Audit(v.Target(), format, args...)
+}
+
+// SetId per Logger.
+func (v *LoggerHandle) SetId(id string) Logger {
+	v.Target().SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *LoggerHandle) SetPriority(pri Priority) Logger {
+	v.Target().SetPriority(pri)
+	return v
+}
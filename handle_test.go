@@ -0,0 +1,62 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoggerHandle(t *testing.T) {
+	var sb1, sb2 strings.Builder
+	lgr1 := newTestLogger(&sb1)
+	lgr2 := newTestLogger(&sb2)
+
+	h := MakeLoggerHandle(lgr1)
+	var lgr Logger = h
+	if p := lgr.SetPriority(Info).Priority(); p != Info || lgr1.Priority() != Info {
+		t.Errorf("priority not delegated: %s", p)
+	}
+	lgr.SetId("h ")
+	lgr.F(Info, "one")
+
+	if old := h.Swap(lgr2); old != lgr1 {
+		t.Errorf("wrong old target")
+	}
+	if h.Target() != lgr2 {
+		t.Errorf("wrong new target")
+	}
+	lgr.F(Debug, "two")
+
+	if s := sb1.String(); s != "h [I] one\n" {
+		t.Errorf("wrong first output: %q", s)
+	}
+	if s := sb2.String(); s != "[D] two\n" {
+		t.Errorf("wrong second output: %q", s)
+	}
+}
+
+func TestLoggerHandleConcurrent(t *testing.T) {
+	var sb strings.Builder
+	h := MakeLoggerHandle(NullLogMaker(nil))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			h.F(Error, "msg %d", i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		h.Swap(newTestLogger(&sb))
+	}()
+	wg.Wait()
+	h.F(Error, "last")
+	if s := sb.String(); !strings.HasSuffix(s, "[E] last\n") {
+		t.Errorf("wrong output: %q", s)
+	}
+}
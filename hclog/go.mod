@@ -0,0 +1,17 @@
+module github.com/pabigot/logwrap/hclog
+
+go 1.17
+
+require (
+	github.com/hashicorp/go-hclog v1.6.3
+	github.com/pabigot/logwrap v0.3.0
+)
+
+require (
+	github.com/fatih/color v1.13.0 // indirect
+	github.com/mattn/go-colorable v0.1.12 // indirect
+	github.com/mattn/go-isatty v0.0.14 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+)
+
+replace github.com/pabigot/logwrap => ../
@@ -0,0 +1,305 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hclog adapts a logwrap.Logger to the hclog.Logger interface from
+// github.com/hashicorp/go-hclog, so components that require an hclog.Logger
+// (such as go-plugin hosts) can share the application's log sink.  It is a
+// separate module so that applications that do not use hclog do not depend
+// on it.
+//
+// hclog levels are mapped to priorities: Trace and Debug to Debug, Info to
+// Info, Warn to Warning, and Error to Error.  Key/value pairs, including
+// those implied by With, are emitted as logwrap.Fields through logwrap.FF,
+// so they are rendered as text only if the logwrap.Logger does not
+// implement logwrap.FieldLogger.
+package hclog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	hc "github.com/hashicorp/go-hclog"
+	lw "github.com/pabigot/logwrap"
+)
+
+// Priority returns the logwrap priority used for messages at level.  Off
+// and NoLevel have no corresponding priority, and return Emerg and Info
+// respectively.
+func Priority(level hc.Level) lw.Priority {
+	switch level {
+	case hc.Trace, hc.Debug:
+		return lw.Debug
+	case hc.Warn:
+		return lw.Warning
+	case hc.Error:
+		return lw.Error
+	case hc.Off:
+		return lw.Emerg
+	}
+	return lw.Info
+}
+
+// Level returns the hclog level corresponding to a logger priority.
+func Level(pri lw.Priority) hc.Level {
+	switch pri {
+	case lw.Debug:
+		return hc.Debug
+	case lw.Info, lw.Notice:
+		return hc.Info
+	case lw.Warning:
+		return hc.Warn
+	}
+	return hc.Error
+}
+
+// Logger implements hclog.Logger by forwarding messages to a logwrap.Logger.
+type Logger struct {
+	lgr     lw.Logger
+	mk      lw.LogMaker
+	name    string
+	implied []interface{}
+	// off is shared by all Logger instances that share lgr.  It is
+	// nonzero when the level has been set to hclog.Off.
+	off *int32
+}
+
+// New returns an hclog.Logger that emits through lgr.
+//
+// Named and ResetNamed create loggers with a different name.  If mk is not
+// nil it is invoked with the new Logger as owner to obtain a logwrap.Logger,
+// which is assigned the name followed by ": " as its identifier and the
+// priority of lgr.  If mk is nil the derived loggers share lgr, so its
+// identifier cannot change, and the name followed by ": " is instead
+// prepended to each message.
+func New(lgr lw.Logger, mk lw.LogMaker) hc.Logger {
+	return &Logger{
+		lgr: lgr,
+		mk:  mk,
+		off: new(int32),
+	}
+}
+
+// Instance provides access to the logwrap.Logger that emits messages.
+func (v *Logger) Instance() lw.Logger {
+	return v.lgr
+}
+
+// Log per hclog.Logger.
+func (v *Logger) Log(level hc.Level, msg string, args ...interface{}) {
+	if level == hc.Off || atomic.LoadInt32(v.off) != 0 {
+		return
+	}
+	pri := Priority(level)
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	if v.mk == nil && v.name != "" {
+		msg = v.name + ": " + msg
+	}
+	var fields lw.Fields
+	if len(v.implied)+len(args) > 0 {
+		fields = make(lw.Fields, (len(v.implied)+len(args)+1)/2)
+		addFields(fields, v.implied)
+		addFields(fields, args)
+	}
+	lw.FF(v.lgr, pri, fields, "%s", msg)
+}
+
+// addFields stores key/value pairs in fields, replacing any existing value
+// for a key.  A trailing key without a value is stored as the value of
+// EXTRA_VALUE_AT_END, as hclog does.  Values created with hclog.Fmt are
+// formatted.
+func addFields(fields lw.Fields, args []interface{}) {
+	for i := 0; i < len(args); i += 2 {
+		var key string
+		var val interface{}
+		if i+1 < len(args) {
+			key = fmt.Sprint(args[i])
+			val = args[i+1]
+		} else {
+			key = hc.MissingKey
+			val = args[i]
+		}
+		if f, ok := val.(hc.Format); ok && len(f) > 0 {
+			val = fmt.Sprintf(fmt.Sprint(f[0]), f[1:]...)
+		}
+		fields[key] = val
+	}
+}
+
+// Trace per hclog.Logger.
+func (v *Logger) Trace(msg string, args ...interface{}) {
+	v.Log(hc.Trace, msg, args...)
+}
+
+// Debug per hclog.Logger.
+func (v *Logger) Debug(msg string, args ...interface{}) {
+	v.Log(hc.Debug, msg, args...)
+}
+
+// Info per hclog.Logger.
+func (v *Logger) Info(msg string, args ...interface{}) {
+	v.Log(hc.Info, msg, args...)
+}
+
+// Warn per hclog.Logger.
+func (v *Logger) Warn(msg string, args ...interface{}) {
+	v.Log(hc.Warn, msg, args...)
+}
+
+// Error per hclog.Logger.
+func (v *Logger) Error(msg string, args ...interface{}) {
+	v.Log(hc.Error, msg, args...)
+}
+
+func (v *Logger) enables(level hc.Level) bool {
+	return atomic.LoadInt32(v.off) == 0 && v.lgr.Priority().Enables(Priority(level))
+}
+
+// IsTrace per hclog.Logger.
+func (v *Logger) IsTrace() bool {
+	return v.enables(hc.Trace)
+}
+
+// IsDebug per hclog.Logger.
+func (v *Logger) IsDebug() bool {
+	return v.enables(hc.Debug)
+}
+
+// IsInfo per hclog.Logger.
+func (v *Logger) IsInfo() bool {
+	return v.enables(hc.Info)
+}
+
+// IsWarn per hclog.Logger.
+func (v *Logger) IsWarn() bool {
+	return v.enables(hc.Warn)
+}
+
+// IsError per hclog.Logger.
+func (v *Logger) IsError() bool {
+	return v.enables(hc.Error)
+}
+
+// ImpliedArgs per hclog.Logger.
+func (v *Logger) ImpliedArgs() []interface{} {
+	return v.implied
+}
+
+// With per hclog.Logger.  The returned logger shares the logwrap.Logger.
+func (v *Logger) With(args ...interface{}) hc.Logger {
+	rv := *v
+	rv.implied = append(append([]interface{}{}, v.implied...), args...)
+	return &rv
+}
+
+// Name per hclog.Logger.  This is the name assigned by Named or
+// ResetNamed, or an empty string if neither has been used.
+func (v *Logger) Name() string {
+	return v.name
+}
+
+// Named per hclog.Logger.  The name is appended to any existing name with a
+// period separator.
+func (v *Logger) Named(name string) hc.Logger {
+	if v.name != "" {
+		name = v.name + "." + name
+	}
+	return v.ResetNamed(name)
+}
+
+// ResetNamed per hclog.Logger.
+func (v *Logger) ResetNamed(name string) hc.Logger {
+	rv := *v
+	rv.name = name
+	if v.mk != nil {
+		rv.lgr = v.mk(&rv)
+		rv.lgr.SetId(name + ": ")
+		rv.lgr.SetPriority(v.lgr.Priority())
+		rv.off = new(int32)
+		atomic.StoreInt32(rv.off, atomic.LoadInt32(v.off))
+	}
+	return &rv
+}
+
+// SetLevel per hclog.Logger.  This changes the priority of the
+// logwrap.Logger.  NoLevel leaves the level unchanged.
+func (v *Logger) SetLevel(level hc.Level) {
+	switch level {
+	case hc.NoLevel:
+	case hc.Off:
+		atomic.StoreInt32(v.off, 1)
+	default:
+		atomic.StoreInt32(v.off, 0)
+		v.lgr.SetPriority(Priority(level))
+	}
+}
+
+// GetLevel per hclog.Logger.
+func (v *Logger) GetLevel() hc.Level {
+	if atomic.LoadInt32(v.off) != 0 {
+		return hc.Off
+	}
+	return Level(v.lgr.Priority())
+}
+
+// StandardLogger per hclog.Logger.
+func (v *Logger) StandardLogger(opts *hc.StandardLoggerOptions) *log.Logger {
+	return log.New(v.StandardWriter(opts), "", 0)
+}
+
+// StandardWriter per hclog.Logger.  Each write is emitted as one message with
+// any trailing newline removed.  Levels are inferred from a leading
+// bracketed level such as "[WARN]" if opts.InferLevels is set; otherwise, or
+// if there is no such prefix, messages are emitted at Info.  A level in
+// opts.ForceLevel takes precedence.  InferLevelsWithTimestamp is not
+// supported.
+func (v *Logger) StandardWriter(opts *hc.StandardLoggerOptions) io.Writer {
+	if opts == nil {
+		opts = &hc.StandardLoggerOptions{}
+	}
+	return &stdWriter{
+		lgr:  v,
+		opts: *opts,
+	}
+}
+
+type stdWriter struct {
+	lgr  *Logger
+	opts hc.StandardLoggerOptions
+}
+
+var levelPrefixes = []struct {
+	pfx   string
+	level hc.Level
+}{
+	{"[TRACE]", hc.Trace},
+	{"[DEBUG]", hc.Debug},
+	{"[INFO]", hc.Info},
+	{"[WARN]", hc.Warn},
+	{"[ERROR]", hc.Error},
+	{"[ERR]", hc.Error},
+}
+
+func (w *stdWriter) Write(data []byte) (int, error) {
+	msg := string(bytes.TrimRight(data, "\n"))
+	level := hc.Info
+	if w.opts.InferLevels || w.opts.ForceLevel != hc.NoLevel {
+		for _, lp := range levelPrefixes {
+			if strings.HasPrefix(msg, lp.pfx) {
+				level = lp.level
+				msg = strings.TrimLeft(msg[len(lp.pfx):], " ")
+				break
+			}
+		}
+	}
+	if w.opts.ForceLevel != hc.NoLevel {
+		level = w.opts.ForceLevel
+	}
+	w.lgr.Log(level, msg)
+	return len(data), nil
+}
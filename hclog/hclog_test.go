@@ -0,0 +1,94 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package hclog
+
+import (
+	"strings"
+	"testing"
+
+	hc "github.com/hashicorp/go-hclog"
+	lw "github.com/pabigot/logwrap"
+)
+
+func newTestMaker(sb *strings.Builder) lw.LogMaker {
+	return func(owner interface{}) lw.Logger {
+		lgr := lw.LogLogMaker(owner)
+		inst := lgr.(*lw.LogLogger).Instance()
+		inst.SetFlags(0)
+		inst.SetOutput(sb)
+		return lgr
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var sb strings.Builder
+	mk := newTestMaker(&sb)
+	var hl hc.Logger = New(mk(nil), mk)
+
+	if l := hl.GetLevel(); l != hc.Warn {
+		t.Errorf("wrong initial level: %s", l)
+	}
+	hl.Info("dropped")
+	hl.Warn("warned", "k", 1, "s", "a b")
+	if s := sb.String(); s != "[W] warned k=1 s=\"a b\"\n" {
+		t.Errorf("wrong warn: %q", s)
+	}
+	sb.Reset()
+
+	hl.SetLevel(hc.Trace)
+	if !hl.IsTrace() || hl.GetLevel() != hc.Debug {
+		t.Errorf("trace not enabled")
+	}
+	wl := hl.With("req", hc.Fmt("%03d", 7))
+	wl.Trace("traced", "dangling")
+	if s := sb.String(); s != "[D] traced EXTRA_VALUE_AT_END=dangling req=007\n" {
+		t.Errorf("wrong trace: %q", s)
+	}
+	sb.Reset()
+
+	nl := wl.Named("sub").Named("leaf")
+	if n := nl.Name(); n != "sub.leaf" {
+		t.Errorf("wrong name: %s", n)
+	}
+	nl.Error("failed")
+	if s := sb.String(); s != "sub.leaf: [E] failed req=007\n" {
+		t.Errorf("wrong named: %q", s)
+	}
+	sb.Reset()
+
+	hl.SetLevel(hc.Off)
+	hl.Error("dropped")
+	if hl.IsError() || hl.GetLevel() != hc.Off {
+		t.Errorf("not off")
+	}
+	if !nl.IsDebug() {
+		t.Errorf("named logger level not independent")
+	}
+	hl.SetLevel(hc.Info)
+
+	sl := hl.StandardLogger(&hc.StandardLoggerOptions{InferLevels: true})
+	sl.Print("[ERROR] bad")
+	sl.Print("plain")
+	hl.StandardWriter(&hc.StandardLoggerOptions{ForceLevel: hc.Warn}).Write([]byte("[INFO] forced\n"))
+	if s := sb.String(); s != "[E] bad\n[I] plain\n[W] forced\n" {
+		t.Errorf("wrong standard: %q", s)
+	}
+	sb.Reset()
+
+	// Key/value pairs reach structured sinks as fields.
+	var ecs strings.Builder
+	el := New(lw.ECSLogMaker(&ecs)(nil), nil)
+	el.With("req", 7).Warn("structured", "user", "bob")
+	if s := ecs.String(); !strings.Contains(s, `"req":7`) || !strings.Contains(s, `"user":"bob"`) {
+		t.Errorf("wrong structured: %q", s)
+	}
+
+	// Without a maker named loggers share the underlying logger and the
+	// name is prepended to the message.
+	shared := New(mk(nil), nil)
+	shared.Named("x").Warn("shared")
+	if s := sb.String(); s != "[W] x: shared\n" {
+		t.Errorf("wrong shared: %q", s)
+	}
+}
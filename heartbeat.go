@@ -0,0 +1,46 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StartHeartbeat starts a goroutine that emits msg through lgr at pri every
+// interval, providing evidence that an otherwise quiet service is still
+// running.  This is a helper that drives an existing logger, not a logger
+// type.
+//
+// The heartbeat stops when ctx is done or the returned function is invoked,
+// whichever comes first.  The returned function waits for the goroutine to
+// exit, so no further heartbeat messages are emitted once it returns; it may
+// be invoked more than once.  If interval is not positive no goroutine is
+// started.
+func StartHeartbeat(ctx context.Context, lgr ImmutableLogger, pri Priority, msg string, interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tkr := time.NewTicker(interval)
+		defer tkr.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tkr.C:
+				lgr.F(pri, "%s", msg)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(cancel)
+		<-done
+	}
+}
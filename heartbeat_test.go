@@ -0,0 +1,39 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartHeartbeat(t *testing.T) {
+	var sb strings.Builder
+	lgr, lch := MakeChanLogger(newTestLogger(&sb), 16)
+
+	stop := StartHeartbeat(context.Background(), lgr, Info, "alive", 5*time.Millisecond)
+	(<-lch).Emit()
+	(<-lch).Emit()
+	stop()
+	stop()
+	for len(lch) > 0 {
+		(<-lch).Emit()
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := len(lch); n != 0 {
+		t.Errorf("heartbeat after stop: %d", n)
+	}
+	if s := sb.String(); !strings.HasPrefix(s, "[I] alive\n[I] alive\n") {
+		t.Errorf("wrong output: %q", s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop = StartHeartbeat(ctx, lgr, Info, "ctx", time.Hour)
+	cancel()
+	stop()
+
+	StartHeartbeat(context.Background(), lgr, Info, "never", 0)()
+}
@@ -0,0 +1,32 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httplog provides consistent access logging of HTTP requests
+// through a logwrap logger.  It is separate from logwrap so that the core
+// package does not depend on net/http.
+package httplog
+
+import (
+	"net/http"
+	"time"
+
+	lw "github.com/pabigot/logwrap"
+)
+
+// LogRequest emits through lgr at priority pri an access log line
+// describing request r which completed with status after dur.  The line has
+// the form:
+//
+//	192.0.2.1:53124 "GET /path?q=1 HTTP/1.1" 200 1.5ms
+//
+// Nothing is formatted if lgr would not emit the message.
+func LogRequest(lgr lw.ImmutableLogger, pri lw.Priority, r *http.Request, status int, dur time.Duration) {
+	if !lgr.Priority().Enables(pri) {
+		return
+	}
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	lgr.F(pri, "%s \"%s %s %s\" %d %s", r.RemoteAddr, r.Method, uri, r.Proto, status, dur)
+}
@@ -0,0 +1,40 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package httplog
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	lw "github.com/pabigot/logwrap"
+)
+
+func TestLogRequest(t *testing.T) {
+	var sb strings.Builder
+	lgr := lw.LogLogMaker(nil)
+	inst := lgr.(*lw.LogLogger).Instance()
+	inst.SetFlags(0)
+	inst.SetOutput(&sb)
+
+	r := httptest.NewRequest("GET", "/path?q=1", nil)
+	LogRequest(lgr, lw.Info, r, 200, time.Millisecond)
+	if s := sb.String(); s != "" {
+		t.Errorf("filtered request logged: %q", s)
+	}
+
+	LogRequest(lgr, lw.Warning, r, 404, 1500*time.Microsecond)
+	if s := sb.String(); s != "[W] 192.0.2.1:1234 \"GET /path?q=1 HTTP/1.1\" 404 1.5ms\n" {
+		t.Errorf("wrong line: %q", s)
+	}
+	sb.Reset()
+
+	// Client-side requests have no RequestURI.
+	r.RequestURI = ""
+	LogRequest(lgr, lw.Error, r, 500, time.Second)
+	if s := sb.String(); s != "[E] 192.0.2.1:1234 \"GET /path?q=1 HTTP/1.1\" 500 1s\n" {
+		t.Errorf("wrong line: %q", s)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// JournaldSocket is the path of the socket on which systemd-journald
+// accepts messages in its native protocol.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// journaldFieldMax is the maximum length of a journal field name.
+const journaldFieldMax = 64
+
+// JournaldLogMaker returns a LogMaker that creates loggers that send
+// messages directly to systemd-journald using its native protocol, along
+// with the connection to the journal, which should be closed when the
+// loggers are no longer used.  An error is returned if the journal socket is
+// not available, e.g. because the process is not running under systemd, so
+// the caller can fall back to another LogMaker.
+//
+// Each message is sent as a journal entry with MESSAGE holding the
+// identifier and formatted text, and PRIORITY holding the syslog level of
+// the message priority.  Created loggers implement FieldLogger; fields are
+// sent as journal fields with names converted to upper case, characters
+// other than letters, digits, and underscores replaced by underscores, and
+// leading underscores removed, as journald reserves them for trusted
+// fields.  Values of fields with keys in DefaultSensitiveKeys are replaced
+// by MaskedValue.  Entries too large to send in a single datagram are dropped.
+// Created loggers have priority Warning.
+func JournaldLogMaker() (LogMaker, net.Conn, error) {
+	return journaldLogMaker(JournaldSocket)
+}
+
+func journaldLogMaker(path string) (LogMaker, net.Conn, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, nil, err
+	}
+	mk := func(interface{}) Logger {
+		return &journaldLogger{
+			conn: conn,
+			pri:  Warning,
+		}
+	}
+	return mk, conn, nil
+}
+
+// journaldLogger sends messages to journald.  All methods are safe for
+// concurrent use.
+type journaldLogger struct {
+	conn *net.UnixConn
+	mu   sync.Mutex
+	id   string
+	pri  Priority
+}
+
+// Priority per ImmutableLogger.
+func (v *journaldLogger) Priority() Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pri
+}
+
+// F per ImmutableLogger.
+func (v *journaldLogger) F(pri Priority, format string, args ...interface{}) {
+	v.FF(pri, nil, format, args...)
+}
+
+// FF per FieldLogger.
+func (v *journaldLogger) FF(pri Priority, fields Fields, format string, args ...interface{}) {
+	v.mu.Lock()
+	id, enabled := v.id, v.pri.Enables(pri)
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	fields = defaultSensitiveKeys.mask(fields)
+	buf := appendJournalField(nil, "MESSAGE", id+fmt.Sprintf(format, args...))
+	buf = appendJournalField(buf, "PRIORITY", fmt.Sprint(pri.Level()))
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if name := journalFieldName(k); name != "" {
+			buf = appendJournalField(buf, name, fmt.Sprint(fields[k]))
+		}
+	}
+	_, _ = v.conn.Write(buf)
+}
+
+// SetId per Logger.
+func (v *journaldLogger) SetId(id string) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.id = id
+	return v
+}
+
+// SetPriority per Logger.
+func (v *journaldLogger) SetPriority(pri Priority) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
+
+// journalFieldName converts key to a valid journal field name, returning an
+// empty string if nothing usable remains.
+func journalFieldName(key string) string {
+	b := []byte(strings.ToUpper(key))
+	for i, c := range b {
+		if !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	s := strings.TrimLeft(string(b), "_")
+	if len(s) > journaldFieldMax {
+		s = s[:journaldFieldMax]
+	}
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = ""
+	}
+	return s
+}
+
+// appendJournalField appends to buf a field in the journald native
+// protocol.  Values that contain a newline use the binary form with an
+// explicit length.
+func appendJournalField(buf []byte, name, value string) []byte {
+	buf = append(buf, name...)
+	if strings.IndexByte(value, '\n') < 0 {
+		buf = append(buf, '=')
+	} else {
+		var n [8]byte
+		binary.LittleEndian.PutUint64(n[:], uint64(len(value)))
+		buf = append(buf, '\n')
+		buf = append(buf, n[:]...)
+	}
+	buf = append(buf, value...)
+	return append(buf, '\n')
+}
@@ -0,0 +1,63 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	tests := []struct{ key, name string }{
+		{"user", "USER"},
+		{"req-id.x", "REQ_ID_X"},
+		{"_trusted", "TRUSTED"},
+		{"9lives", ""},
+		{"__", ""},
+	}
+	for _, tc := range tests {
+		if s := journalFieldName(tc.key); s != tc.name {
+			t.Errorf("%q: got %q, expected %q", tc.key, s, tc.name)
+		}
+	}
+}
+
+func TestJournaldLogMaker(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unixgram sockets")
+	}
+	path := filepath.Join(t.TempDir(), "socket")
+	if _, _, err := journaldLogMaker(path); err == nil {
+		t.Errorf("missing socket not detected")
+	}
+
+	srv, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer srv.Close()
+	mk, conn, err := journaldLogMaker(path)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	lgr := mk(nil)
+	lgr.SetId("svc: ")
+	lgr.F(Info, "dropped")
+	FF(lgr, Error, Fields{"user": "bob", "note": "a\nb", "secret": "s"}, "failed %d", 3)
+
+	buf := make([]byte, 1024)
+	n, err := srv.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	exp := "MESSAGE=svc: failed 3\nPRIORITY=3\n" +
+		"NOTE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\nSECRET=***\nUSER=bob\n"
+	if s := string(buf[:n]); s != exp {
+		t.Errorf("wrong entry: %q", s)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// LineWriter is an io.WriteCloser that emits each line written to it as a
+// separate message through a logger.  This allows output from code that
+// writes text streams, such as subprocesses, to be captured in the log.
+//
+// Text that does not end with a newline is buffered until the newline
+// arrives in a later write, or until Close is invoked.  Line terminators,
+// including a carriage return preceding the newline, are not included in
+// the messages.  All methods are safe for concurrent use.
+type LineWriter struct {
+	lgr ImmutableLogger
+	pri Priority
+	// rules, if not empty, select the priority for each line.
+	rules []PriorityRule
+	mu    sync.Mutex
+	buf   []byte
+}
+
+// MakeLineWriter returns a LineWriter that emits lines through lgr at pri.
+func MakeLineWriter(lgr ImmutableLogger, pri Priority) *LineWriter {
+	return &LineWriter{
+		lgr: lgr,
+		pri: pri,
+	}
+}
+
+// StreamWriter returns a pair of LineWriters that emit through lgr at
+// stdoutPri and stderrPri respectively.  They are suitable for use as the
+// Stdout and Stderr of an exec.Cmd, e.g. with Info and Error to record the
+// normal output and complaints of a supervised child process.  Both should
+// be closed after the process completes to flush any incomplete final line.
+func StreamWriter(lgr ImmutableLogger, stdoutPri, stderrPri Priority) (stdout, stderr *LineWriter) {
+	return MakeLineWriter(lgr, stdoutPri), MakeLineWriter(lgr, stderrPri)
+}
+
+// InferringLineWriter returns a LineWriter that determines the priority of
+// each line from its content, preserving the severity information in text
+// from a source that labels its own lines, e.g. with "WARN:".  Each line is
+// emitted at the priority associated with a pattern that matches it, or at
+// def if no pattern matches.  Anchor patterns with "^" to match only a
+// prefix.  Lines are emitted intact, including the matched text.
+//
+// If a line matches several patterns the most severe of their priorities is
+// used.  The patterns map is not retained.
+func InferringLineWriter(lgr ImmutableLogger, patterns map[*regexp.Regexp]Priority, def Priority) *LineWriter {
+	rules := make([]PriorityRule, 0, len(patterns))
+	for re, pri := range patterns {
+		rules = append(rules, PriorityRule{
+			Pattern: re,
+			To:      pri,
+		})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].To < rules[j].To
+	})
+	w := MakeLineWriter(lgr, def)
+	w.rules = rules
+	return w
+}
+
+// Write per io.Writer.  It always consumes all of data.
+func (w *LineWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(data)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		line := data[:i]
+		if len(w.buf) > 0 {
+			w.buf = append(w.buf, line...)
+			line = w.buf
+		}
+		w.emit(line)
+		w.buf = w.buf[:0]
+		data = data[i+1:]
+	}
+	w.buf = append(w.buf, data...)
+	return n, nil
+}
+
+// Close per io.Closer.  It emits any buffered incomplete line.  The writer
+// may continue to be used after Close.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = w.buf[:0]
+	}
+	return nil
+}
+
+// emit logs line without any trailing carriage return, at the priority
+// selected by the rules if any.  The caller must hold w.mu.
+func (w *LineWriter) emit(line []byte) {
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	pri := w.pri
+	for _, r := range w.rules {
+		if r.Pattern.Match(line) {
+			pri = r.To
+			break
+		}
+	}
+	w.lgr.F(pri, "%s", line)
+}
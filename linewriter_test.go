@@ -0,0 +1,75 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLineWriter(t *testing.T) {
+	var sb strings.Builder
+	w := MakeLineWriter(newTestLogger(&sb), Info)
+
+	for _, s := range []string{"one\ntw", "o", "\r\n\nthr", "ee"} {
+		if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+			t.Errorf("write %q: %d %v", s, n, err)
+		}
+	}
+	if s := sb.String(); s != "[I] one\n[I] two\n[I] \n" {
+		t.Errorf("wrong buffered output: %q", s)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("close: %v", err)
+	}
+	if s := sb.String(); !strings.HasSuffix(s, "[I] three\n") {
+		t.Errorf("wrong flushed output: %q", s)
+	}
+	sb.Reset()
+	w.Close()
+	if s := sb.String(); s != "" {
+		t.Errorf("empty close emitted: %q", s)
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell")
+	}
+	var sb strings.Builder
+	stdout, stderr := StreamWriter(newTestLogger(&sb), Info, Error)
+	cmd := exec.Command(sh, "-c", "echo out; echo err >&2; printf tail")
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	stdout.Close()
+	stderr.Close()
+	// The streams are copied concurrently so only the order within
+	// each stream is defined.
+	lines := strings.Split(sb.String(), "\n")
+	sort.Strings(lines)
+	if s := strings.Join(lines, "|"); s != "|[E] err|[I] out|[I] tail" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestInferringLineWriter(t *testing.T) {
+	var sb strings.Builder
+	w := InferringLineWriter(newTestLogger(&sb), map[*regexp.Regexp]Priority{
+		regexp.MustCompile(`^WARN:`):  Warning,
+		regexp.MustCompile(`^ERROR:`): Error,
+		regexp.MustCompile(`fatal`):   Crit,
+	}, Info)
+	w.Write([]byte("starting\nWARN: low disk\nERROR: fatal io\nok WARN: not prefix\n"))
+	exp := "[I] starting\n[W] WARN: low disk\n[C] ERROR: fatal io\n[I] ok WARN: not prefix\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+}
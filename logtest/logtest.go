@@ -0,0 +1,143 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logtest provides a conformance suite that verifies a
+// logwrap.Logger implementation honors the documented contract of the
+// logwrap interfaces.  Authors of Logger implementations and decorators can
+// invoke RunConformance from their own tests.
+package logtest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	lw "github.com/pabigot/logwrap"
+)
+
+// Recorder is passed as the owner to the LogMaker under test.  A LogMaker
+// that directs the output of the loggers it creates to the recorder allows
+// RunConformance to verify what is emitted, not just the behavior of the
+// Logger methods.  Recorder is an io.Writer that is safe for concurrent use.
+type Recorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write per io.Writer.
+func (r *Recorder) Write(data []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(data)
+}
+
+// String returns everything written to the recorder.
+func (r *Recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.String()
+}
+
+// Reset discards everything written to the recorder.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf.Reset()
+}
+
+// priorities lists all valid priorities in order of decreasing severity.
+var priorities = []lw.Priority{
+	lw.Emerg, lw.Crit, lw.Error, lw.Warning, lw.Notice, lw.Info, lw.Debug,
+}
+
+// marker returns text that identifies a message emitted at pri while the
+// logger was set to cur.
+func marker(cur, pri lw.Priority) string {
+	return fmt.Sprintf("conformance-%s-%s-end", cur, pri)
+}
+
+// RunConformance verifies that loggers created by mk satisfy the Logger
+// contract:
+//
+//   - the initial priority is Warning and no identifier is assigned;
+//   - SetPriority and SetId return a usable Logger, and SetPriority changes
+//     the value returned by Priority;
+//   - F accepts messages at every priority regardless of the logger
+//     priority, and formats its arguments.
+//
+// Each logger is created by invoking mk with a new *Recorder as the owner.
+// If mk directs output to the recorder RunConformance additionally verifies
+// that exactly the messages enabled by the logger priority are emitted,
+// with their formatted text, and that the identifier assigned by SetId
+// appears in emitted messages.  Otherwise those checks are skipped and
+// noted in the test log.
+func RunConformance(t testing.TB, mk lw.LogMaker) {
+	t.Helper()
+
+	rec := &Recorder{}
+	lgr := mk(rec)
+	if lgr == nil {
+		t.Fatalf("LogMaker returned nil")
+	}
+	if p := lgr.Priority(); p != lw.Warning {
+		t.Errorf("initial priority %s, expected Warning", p)
+	}
+
+	lgr.F(lw.Warning, "%s %d", "probe", 42)
+	capture := rec.String() != ""
+	if capture {
+		out := rec.String()
+		if !strings.Contains(out, "probe 42") {
+			t.Errorf("message not formatted: %q", out)
+		}
+		if strings.Contains(out, "conformance-id") {
+			t.Errorf("identifier present before SetId: %q", out)
+		}
+	} else {
+		t.Logf("output not directed to the *logtest.Recorder owner; skipping output checks")
+	}
+
+	for _, cur := range priorities {
+		if l2 := lgr.SetPriority(cur); l2 == nil {
+			t.Fatalf("SetPriority(%s) returned nil", cur)
+		}
+		if p := lgr.Priority(); p != cur {
+			t.Errorf("Priority after SetPriority(%s) is %s", cur, p)
+		}
+		rec.Reset()
+		for _, pri := range priorities {
+			lgr.F(pri, "%s", marker(cur, pri))
+		}
+		if !capture {
+			continue
+		}
+		out := rec.String()
+		for _, pri := range priorities {
+			emitted := strings.Contains(out, marker(cur, pri))
+			if want := cur.Enables(pri); emitted != want {
+				t.Errorf("at %s, message at %s emitted %t, expected %t", cur, pri, emitted, want)
+			}
+		}
+	}
+
+	lgr.SetPriority(lw.Info)
+	if l2 := lgr.SetId("conformance-id"); l2 == nil {
+		t.Fatalf("SetId returned nil")
+	}
+	rec.Reset()
+	lgr.F(lw.Info, "identified")
+	if capture {
+		out := rec.String()
+		if !strings.Contains(out, "conformance-id") || !strings.Contains(out, "identified") {
+			t.Errorf("identifier not emitted: %q", out)
+		}
+	}
+
+	// A second logger from the same maker must be independent.
+	other := mk(&Recorder{})
+	if p := other.Priority(); p != lw.Warning {
+		t.Errorf("second logger priority %s, expected Warning", p)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logtest
+
+import (
+	"io"
+	"testing"
+
+	lw "github.com/pabigot/logwrap"
+)
+
+// recordingLogMaker creates LogLogger instances that write to the owner.
+func recordingLogMaker(owner interface{}) lw.Logger {
+	lgr := lw.LogLogMaker(owner)
+	if w, ok := owner.(io.Writer); ok {
+		lgr.(*lw.LogLogger).SetOutput(w)
+	}
+	return lgr
+}
+
+func TestRunConformance(t *testing.T) {
+	makers := map[string]lw.LogMaker{
+		"LogLogger":  recordingLogMaker,
+		"NullLogger": lw.NullLogMaker,
+		"MaxLineLogger": func(owner interface{}) lw.Logger {
+			return lw.MakeMaxLineLogger(recordingLogMaker(owner), 200)
+		},
+		"QuietLogger": func(owner interface{}) lw.Logger {
+			return lw.MakeQuietLogger(recordingLogMaker(owner))
+		},
+		"ECSLogger": func(owner interface{}) lw.Logger {
+			return lw.ECSLogMaker(owner.(io.Writer))(owner)
+		},
+	}
+	for name, mk := range makers {
+		t.Run(name, func(t *testing.T) {
+			RunConformance(t, mk)
+		})
+	}
+}
@@ -16,11 +16,20 @@
 package logwrap
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Priority distinguishes log message priority.  Higher priority messages have
@@ -60,8 +69,16 @@ var (
 // ParsePriority accepts strings of any case corresponding to Priority
 // identifiers and returns the corresponding Priority value paired with true.
 // If the string does not identify a priority the returned boolean will be
-// false.
+// false.  Aliases installed with SetPriorityAliases are also accepted.
 func ParsePriority(s string) (pri Priority, ok bool) {
+	if pa := ActivePriorityAliases(); pa != nil {
+		return pa.Parse(s)
+	}
+	return parseBuiltinPriority(s)
+}
+
+// parseBuiltinPriority implements ParsePriority for the built-in names.
+func parseBuiltinPriority(s string) (pri Priority, ok bool) {
 	ok = true
 	switch strings.ToLower(s) {
 	default:
@@ -139,6 +156,19 @@ func (p *Priority) UnmarshalText(text []byte) error {
 	return p.Set(string(text))
 }
 
+// Level returns the numeric severity of the priority as defined by syslog(3),
+// e.g. 3 for Error and 7 for Debug.  Emerg is 0.  The value for an unset
+// priority is -1.
+func (p Priority) Level() int {
+	switch p {
+	case unsetPriority:
+		return -1
+	case Emerg:
+		return 0
+	}
+	return int(p)
+}
+
 // Enables returns true if and only if a logger set to the receiver's priority
 // should emit log messages at priority p2.  For example Info.Enables(Crit) is
 // true, but Warning.Enables(Debug) is false.
@@ -151,13 +181,81 @@ func (p Priority) Enables(p2 Priority) bool {
 type Logf func(format string, args ...interface{})
 
 // MakePriWrapper creates Logf functions bound to the given logger and
-// priority.
+// priority.  Use MakePriLogf where the bound priority must be recoverable.
 func MakePriWrapper(lgr ImmutableLogger, pri Priority) Logf {
 	return func(format string, args ...interface{}) {
 		lgr.F(pri, format, args...)
 	}
 }
 
+// PriLogf is a printf-like function bound to a logger and a priority, packaged
+// so the priority can be inspected.  Middleware that accepts a PriLogf can
+// behave differently depending on the severity of the messages it forwards.
+type PriLogf interface {
+	// Priority returns the priority at which Logf emits messages.
+	Priority() Priority
+
+	// Logf emits a message at the bound priority.  The method value
+	// is a Logf.
+	Logf(format string, args ...interface{})
+}
+
+type priLogf struct {
+	lgr ImmutableLogger
+	pri Priority
+}
+
+// MakePriLogf creates a PriLogf bound to the given logger and priority.
+func MakePriLogf(lgr ImmutableLogger, pri Priority) PriLogf {
+	return &priLogf{
+		lgr: lgr,
+		pri: pri,
+	}
+}
+
+// Priority per PriLogf.
+func (v *priLogf) Priority() Priority {
+	return v.pri
+}
+
+// Logf per PriLogf.
+func (v *priLogf) Logf(format string, args ...interface{}) {
+	v.lgr.F(v.pri, format, args...)
+}
+
+type boundLogger struct {
+	lgr ImmutableLogger
+	pri Priority
+}
+
+// BindPriority returns an ImmutableLogger that emits every message through
+// lgr at pri, ignoring the priority passed to its F method.  Messages remain
+// subject to the filtering of lgr.  This narrows a logger passed to a helper
+// that should only ever log at one priority, while still satisfying APIs
+// that require an ImmutableLogger.
+func BindPriority(lgr ImmutableLogger, pri Priority) ImmutableLogger {
+	return &boundLogger{
+		lgr: lgr,
+		pri: pri,
+	}
+}
+
+// Priority per ImmutableLogger.  This is the priority of the underlying
+// logger, not the bound priority.
+func (v *boundLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  pri is ignored.
+func (v *boundLogger) F(pri Priority, format string, args ...interface{}) {
+	v.lgr.F(v.pri, format, args...)
+}
+
+// Audit per Auditor.
+func (v *boundLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
 // PriPr provides LogF implementations for each possible priority.
 //
 // This structure simplifies the common need for short-hand loggers at
@@ -207,6 +305,9 @@ type PriPr struct {
 	I Logf
 	// D logs its arguments at Debug priority.
 	D Logf
+
+	// lgr is the logger used by MakePriPr, if any.
+	lgr ImmutableLogger
 }
 
 // MakePriPri returns a PriPr structure that logs at each priority using lgr.
@@ -219,7 +320,71 @@ func MakePriPr(lgr ImmutableLogger) PriPr {
 		N:  MakePriWrapper(lgr, Notice),
 		I:  MakePriWrapper(lgr, Info),
 		D:  MakePriWrapper(lgr, Debug),
+
+		lgr: lgr,
+	}
+}
+
+// At returns a Logf that emits at pri through the same logger as the other
+// functions of lpr.  This allows the severity of a specific message to be
+// chosen at runtime, e.g. to escalate a normally informational message:
+//
+//	lpr.At(pri)("retry %d of %d", n, limit)
+//
+// Each call allocates a new closure; cache the result where it is used
+// repeatedly.  For a PriPr not created by MakePriPr the function is the
+// field for pri, and an unrecognized priority yields a Logf that discards
+// its messages.
+func (lpr PriPr) At(pri Priority) Logf {
+	if lpr.lgr != nil {
+		return MakePriWrapper(lpr.lgr, pri)
 	}
+	var rv Logf
+	switch pri {
+	case Emerg:
+		rv = lpr.Em
+	case Crit:
+		rv = lpr.C
+	case Error:
+		rv = lpr.E
+	case Warning:
+		rv = lpr.W
+	case Notice:
+		rv = lpr.N
+	case Info:
+		rv = lpr.I
+	case Debug:
+		rv = lpr.D
+	}
+	if rv == nil {
+		rv = nopLogf
+	}
+	return rv
+}
+
+// nopLogf is a Logf that discards its message.
+func nopLogf(string, ...interface{}) {}
+
+// nopPriPr is a PriPr that discards all messages.
+var nopPriPr = PriPr{
+	Em: nopLogf,
+	C:  nopLogf,
+	E:  nopLogf,
+	W:  nopLogf,
+	N:  nopLogf,
+	I:  nopLogf,
+	D:  nopLogf,
+}
+
+// If returns lpr if cond is true, and otherwise a PriPr that discards all
+// messages without formatting them.  This is the PriPr equivalent of FIf:
+//
+//	lpr.If(sampled).D("conn %s state %s", conn, state)
+func (lpr PriPr) If(cond bool) PriPr {
+	if cond {
+		return lpr
+	}
+	return nopPriPr
 }
 
 // ImmutableLogger provides the key functionality for emitting filterable
@@ -261,6 +426,209 @@ type LogOwner interface {
 	LogSetPriority(pri Priority)
 }
 
+// TimedLogger is implemented by loggers that can emit messages stamped with
+// a caller-supplied time rather than the current time.  This supports
+// replaying or backfilling historical events.
+type TimedLogger interface {
+	ImmutableLogger
+
+	// FAt formats a message and emits it to the log with timestamp t,
+	// subject to the same filtering as F.
+	FAt(t time.Time, pri Priority, format string, args ...interface{})
+}
+
+// FAt emits a message through lgr stamped with time t.  If lgr does not
+// implement TimedLogger the message is emitted with F, and t is rendered in
+// RFC 3339 format at the start of the message so it is not lost.
+func FAt(lgr ImmutableLogger, t time.Time, pri Priority, format string, args ...interface{}) {
+	if tl, ok := lgr.(TimedLogger); ok {
+		tl.FAt(t, pri, format, args...)
+	} else if lgr.Priority().Enables(pri) {
+		lgr.F(pri, "%s %s", t.Format(time.RFC3339Nano), fmt.Sprintf(format, args...))
+	}
+}
+
+// RequestLogger is implemented by loggers that can tag individual messages
+// with a request identifier supplied at the point the message is logged.
+// This allows messages to be correlated by request even when they are
+// emitted in a different goroutine, as with MakeChanLogger.
+type RequestLogger interface {
+	ImmutableLogger
+
+	// FRequest formats a message and emits it to the log tagged with
+	// rid, subject to the same filtering as F.
+	FRequest(rid string, pri Priority, format string, args ...interface{})
+}
+
+// FRequest emits through lgr a message tagged with request identifier rid.
+// The identifier is rendered within square brackets at the start of the
+// message, following any prefix supplied through PrefixedChanLogger.  If lgr
+// does not implement RequestLogger the tag is added before invoking F.
+func FRequest(lgr ImmutableLogger, rid string, pri Priority, format string, args ...interface{}) {
+	if rl, ok := lgr.(RequestLogger); ok {
+		rl.FRequest(rid, pri, format, args...)
+	} else if lgr.Priority().Enables(pri) {
+		lgr.F(pri, "[%s] %s", rid, fmt.Sprintf(format, args...))
+	}
+}
+
+// FIf emits a message through lgr only if cond is true.  When cond is false
+// the message is neither formatted nor submitted to lgr.  This supports
+// messages that depend on runtime state other than priority, such as
+// logging only for a sampled set of connections.
+func FIf(lgr ImmutableLogger, cond bool, pri Priority, format string, args ...interface{}) {
+	if cond {
+		lgr.F(pri, format, args...)
+	}
+}
+
+// ContextValueLogger is implemented by loggers that can use an opaque value
+// supplied with each message, such as a trace span or a tenant object, for
+// example to annotate or route the message.
+type ContextValueLogger interface {
+	ImmutableLogger
+
+	// FContextValue formats a message and emits it to the log along
+	// with cv, subject to the same filtering as F.
+	FContextValue(cv interface{}, pri Priority, format string, args ...interface{})
+}
+
+// FContextValue emits through lgr a message accompanied by the context
+// value cv.  If lgr does not implement ContextValueLogger cv is discarded
+// and the message is emitted with F.
+//
+// Loggers constructed by MakeChanLogger capture cv with the message and
+// pass it to the underlying logger when the message is emitted, so it
+// reaches a ContextValueLogger sink in the consumer goroutine.  The value
+// is captured by reference, not copied: the caller must not mutate it after
+// submitting the message.
+func FContextValue(lgr ImmutableLogger, cv interface{}, pri Priority, format string, args ...interface{}) {
+	if cl, ok := lgr.(ContextValueLogger); ok {
+		cl.FContextValue(cv, pri, format, args...)
+	} else {
+		lgr.F(pri, format, args...)
+	}
+}
+
+// GuardedLogger is implemented by loggers that can decide whether a message
+// is enabled and produce it as a single operation, so that a concurrent
+// change to the logger's priority cannot intervene between the check and
+// the emission.  This is a building block for stateful decorators that must
+// act only on messages that are actually emitted, as EmissionLogger does.
+type GuardedLogger interface {
+	ImmutableLogger
+
+	// FGuarded emits the message returned by msg if and only if pri is
+	// enabled.  msg is invoked only if the message will be emitted, and
+	// is invoked while the logger holds its internal lock, so it must
+	// not use the logger and should be brief.
+	FGuarded(pri Priority, msg func() string)
+}
+
+// FGuarded emits through lgr the message produced by msg, invoking msg only
+// if pri is enabled.  If lgr does not implement GuardedLogger the priority
+// is checked and the message emitted with F as separate operations, so a
+// concurrent priority change may intervene.
+func FGuarded(lgr ImmutableLogger, pri Priority, msg func() string) {
+	if gl, ok := lgr.(GuardedLogger); ok {
+		gl.FGuarded(pri, msg)
+	} else if lgr.Priority().Enables(pri) {
+		lgr.F(pri, "%s", msg())
+	}
+}
+
+// CheckedLogger is implemented by loggers that can report whether a message
+// was successfully written to their output.  This allows decorators to react
+// to failures of the underlying sink, e.g. by redirecting the message.
+type CheckedLogger interface {
+	ImmutableLogger
+
+	// FChecked formats a message and emits it to the log, subject to
+	// the same filtering as F, returning any error that prevented the
+	// message from being written.  A message that is filtered out is not
+	// an error.
+	FChecked(pri Priority, format string, args ...interface{}) error
+}
+
+// FChecked emits through lgr a message and returns any error that prevented
+// it from being written.  If lgr does not implement CheckedLogger the
+// message is emitted with F and the result is nil.
+func FChecked(lgr ImmutableLogger, pri Priority, format string, args ...interface{}) error {
+	if cl, ok := lgr.(CheckedLogger); ok {
+		return cl.FChecked(pri, format, args...)
+	}
+	lgr.F(pri, format, args...)
+	return nil
+}
+
+// AuditLabel is the indicator used in place of the priority for audit
+// messages.
+const AuditLabel = "AUDIT"
+
+// auditPriority is used internally to identify audit messages.
+const auditPriority Priority = -1
+
+// Auditor is implemented by loggers that support audit messages.  Audit
+// events such as logins and configuration changes are not a severity: they
+// must be recorded regardless of the priority used to filter other
+// messages.
+type Auditor interface {
+	// Audit formats a message and emits it to the log without
+	// filtering, tagged so it is distinguishable from other messages.
+	Audit(format string, args ...interface{})
+}
+
+// Audit emits an audit message through lgr.  If lgr does not implement
+// Auditor the message is prefixed with "[AUDIT] " and emitted with F at
+// Emerg, the one priority that every logger enables.
+//
+// LogLogger and the decorators and channel loggers provided by this package
+// implement Auditor, passing audit messages to the logger they wrap without
+// filtering, rate limiting, or deduplication.
+func Audit(lgr ImmutableLogger, format string, args ...interface{}) {
+	if al, ok := lgr.(Auditor); ok {
+		al.Audit(format, args...)
+	} else {
+		lgr.F(Emerg, "[%s] %s", AuditLabel, fmt.Sprintf(format, args...))
+	}
+}
+
+// DefaultIdSeparator is placed between identifiers composed by AppendId
+// unless a logger specifies otherwise.
+const DefaultIdSeparator = "."
+
+// IdAppender is implemented by loggers that can extend their identifier,
+// rather than replacing it as SetId does.  This supports hierarchical
+// identifiers like "svc.sub.worker" built up as a logger is passed down
+// through the layers of a component.
+type IdAppender interface {
+	// AppendId adds id to the end of the current identifier, separated
+	// from it by a logger-specific separator.  If the current identifier
+	// ends with whitespace the whitespace is moved to follow id, so
+	// "svc " extended with "sub" becomes "svc.sub ".  If there is no
+	// current identifier this is equivalent to SetId.  A subsequent SetId
+	// replaces the composed identifier.
+	AppendId(id string) Logger
+}
+
+// AppendId extends the identifier of lgr with id.  If lgr does not implement
+// IdAppender its identifier is replaced with SetId.
+func AppendId(lgr Logger, id string) Logger {
+	if ia, ok := lgr.(IdAppender); ok {
+		return ia.AppendId(id)
+	}
+	return lgr.SetId(id)
+}
+
+// composeId implements the IdAppender rules for combining identifiers.
+func composeId(cur, sep, id string) string {
+	base := strings.TrimRight(cur, " \t")
+	if base == "" {
+		return id
+	}
+	return base + sep + id + cur[len(base):]
+}
+
 // A LogMaker is a factory function that constructs a logger instance for some
 // object or operation.  It allows the selection of a log infrastructure to be
 // injected into a package in a way that ensures active objects created by the
@@ -292,6 +660,11 @@ func (v *nullLogger) SetId(id string) Logger {
 	return v
 }
 
+// AppendId per IdAppender.
+func (v *nullLogger) AppendId(id string) Logger {
+	return v
+}
+
 // SetPriority per Logger.
 func (v *nullLogger) SetPriority(pri Priority) Logger {
 	*v = nullLogger(pri)
@@ -299,13 +672,105 @@ func (v *nullLogger) SetPriority(pri Priority) Logger {
 }
 
 // LogLogger uses a dedicated instance of log.Logger.
+//
+// All LogLogger methods are safe for concurrent use: configuration is
+// protected by an internal mutex, so loggers can be shared among goroutines
+// without a channel logger.
+//
+// Each emitted message, including its prefix, timestamp, priority label,
+// and trailing newline, is rendered completely into a buffer and passed to
+// the output in exactly one Write call made while holding the mutex.
+// Concurrent calls to the same LogLogger therefore never interleave partial
+// lines, even if the output writer does no synchronization of its own.
 type LogLogger struct {
-	lgr *log.Logger
-	pri Priority
+	mu       sync.Mutex
+	lgr      *log.Logger
+	pri      Priority
+	noPriPfx bool
+	label    LabelStyle
+	// priNotices enables logging of priority changes.
+	priNotices bool
+	idSep      string
+	padLabel   bool
+	errSink    ImmutableLogger
+	// auditSink receives audit messages if not nil.
+	auditSink ImmutableLogger
+	idColor   ColorMode
+	tty       ttyCache
+	// stackDepth enables the call stack depth indicator.
+	stackDepth bool
+	// fieldFilter restricts fields rendered by FF, if not nil.
+	fieldFilter fieldFilter
+	fieldFmt    fieldFormat
+	// priFlags overrides the log.Logger flags for specific priorities.
+	priFlags map[Priority]int
+	// argLimit bounds the rendered length of each argument, if positive.
+	argLimit int
+	// progress is set while the last output was an unterminated
+	// progress line.
+	progress bool
+	// emoji holds the symbols for LabelEmoji, or is nil to use
+	// DefaultLabelEmoji.
+	emoji map[Priority]string
+	// tag is rendered before the identifier if not empty.
+	tag string
+	// pfxCache holds the rendered identifier and priority labels.
+	pfxCache prefixCache
+	// overhead accumulates measurements for overhead reports, if not nil.
+	overhead *overheadStats
+	// sensitive holds the keys of fields to mask, or is nil to use
+	// DefaultSensitiveKeys.
+	sensitive sensitiveKeys
+	// fallback renders malformed calls with formatFallback.
+	fallback bool
+
+	// revertPri replaces pri once revertAt is reached, unless revertAt is
+	// zero.
+	revertPri Priority
+	revertAt  time.Time
+
+	// capMu serializes CaptureOutput.
+	capMu sync.Mutex
+}
+
+// LabelStyle selects how LogLogger renders the priority indicator within
+// the square brackets that prefix each message.
+type LabelStyle int
+
+const (
+	// LabelLetter renders the first letter of the priority name, or '!'
+	// for Emerg, e.g. "[E]".  This is the default.
+	LabelLetter LabelStyle = iota
+	// LabelLevel renders the syslog severity number from Level, e.g.
+	// "[3]".
+	LabelLevel
+	// LabelLevelLetter renders the severity number followed by the
+	// letter, e.g. "[3E]", which sorts by severity and remains readable.
+	LabelLevelLetter
+	// LabelName renders the priority name, e.g. "[Error]".
+	LabelName
+	// LabelEmoji renders a symbol selected with SetLabelEmoji, e.g.
+	// "[🟥]", which is quicker to scan on dashboards that display
+	// emoji.  Priorities without a symbol are rendered as for
+	// LabelLetter.
+	LabelEmoji
+)
+
+// DefaultLabelEmoji is the symbol for each priority used by the LabelEmoji
+// style unless replaced with SetLabelEmoji.
+var DefaultLabelEmoji = map[Priority]string{
+	Emerg:   "\U0001F6A8", // police car light
+	Crit:    "\U0001F6D1", // stop sign
+	Error:   "\U0001F7E5", // red square
+	Warning: "\U0001F7E8", // yellow square
+	Notice:  "\U0001F7E6", // blue square
+	Info:    "\U0001F7E9", // green square
+	Debug:   "\u2B1C",     // white square
 }
 
 // LogLogMaker returns a Logger that uses a dedicated instance of the core
-// log.Logger type to emit messages via the Print API.  The initial priority
+// log.Logger type to emit messages.  The log.Logger flags, prefix, and
+// output are honored as they would be by its Print API.  The initial priority
 // is Warning.
 func LogLogMaker(interface{}) Logger {
 	return &LogLogger{
@@ -326,35 +791,792 @@ var priMap = map[Priority]string{
 
 // Priority per ImmutableLogger.
 func (v *LogLogger) Priority() Priority {
-	return v.pri
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.priority()
 }
 
 // F per ImmutableLogger.  Priorities are represented in the messages as the
 // first letter of the priority (or '!' for Emerg) within square brackets
-// prefixing the formatted message.
+// prefixing the formatted message, unless disabled with SetPriorityPrefix.
 func (v *LogLogger) F(pri Priority, format string, args ...interface{}) {
-	if v.pri.Enables(pri) {
-		s := fmt.Sprintf(format, args...)
-		v.lgr.Printf("[%s] %s", priMap[pri], s)
+	v.mu.Lock()
+	enabled := v.priority().Enables(pri)
+	mf := v.formatting()
+	measured := v.overhead != nil
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	var t0, t1 time.Time
+	if measured {
+		t0 = time.Now()
+	}
+	s := mf.sprintf(format, args)
+	if measured {
+		t1 = time.Now()
+	}
+	var err error
+	v.mu.Lock()
+	if measured && v.overhead != nil {
+		err = v.measuredOutput(t0, t1, pri, s)
+	} else {
+		err = v.output(time.Now(), 2, pri, s)
+	}
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// prepare determines whether a message at pri is enabled and if so
+// formats it.  v.mu must not be held: it is taken only to read the
+// configuration, so that arguments whose String methods log to v do not
+// deadlock.
+func (v *LogLogger) prepare(pri Priority, format string, args []interface{}) (string, bool) {
+	v.mu.Lock()
+	enabled := v.priority().Enables(pri)
+	mf := v.formatting()
+	v.mu.Unlock()
+	if !enabled {
+		return "", false
+	}
+	return mf.sprintf(format, args), true
+}
+
+// FChecked per CheckedLogger.  Write errors are returned to the caller
+// rather than being passed to the error sink.
+func (v *LogLogger) FChecked(pri Priority, format string, args ...interface{}) error {
+	s, ok := v.prepare(pri, format, args)
+	if !ok {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.output(time.Now(), 2, pri, s)
+}
+
+// eraseLine is the ANSI escape sequence that clears from the cursor to the
+// end of the line.
+const eraseLine = "\x1b[K"
+
+// FProgress emits a message intended to show the progress of an operation
+// in place.  If the output is a terminal the message is written preceded by
+// a carriage return and followed by an escape sequence that clears the rest
+// of the line, with no trailing newline, so each progress message replaces
+// the previous one.  The next message emitted by other methods first
+// terminates the progress line so it remains visible.
+//
+// Terminal detection is the same as for ColorAuto: the output must be an
+// *os.File that refers to a character device.  For any other output, such
+// as a file or pipe, the message is emitted as with F, so each update
+// appears as a separate line.
+func (v *LogLogger) FProgress(pri Priority, format string, args ...interface{}) {
+	s, ok := v.prepare(pri, format, args)
+	if !ok {
+		return
+	}
+	var err error
+	v.mu.Lock()
+	if v.tty.check(v.lgr.Writer()) {
+		buf := append([]byte{'\r'}, v.render(nil, time.Now(), 2, pri, s)...)
+		buf = append(buf, eraseLine...)
+		_, err = v.lgr.Writer().Write(buf)
+		v.progress = true
+	} else {
+		err = v.output(time.Now(), 2, pri, s)
+	}
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// FAt per TimedLogger.  The message is rendered as with F, but any date and
+// time required by the log.Logger flags are taken from t.
+func (v *LogLogger) FAt(t time.Time, pri Priority, format string, args ...interface{}) {
+	s, ok := v.prepare(pri, format, args)
+	if !ok {
+		return
+	}
+	v.mu.Lock()
+	err := v.output(t, 2, pri, s)
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// FGuarded per GuardedLogger.  The priority check, the invocation of msg,
+// and the output are done while holding the lock that serializes output, so
+// msg must not use v.  Unlike the arguments to F, the message is not
+// subject to the limits set with SetArgLimit or the format fallback.
+func (v *LogLogger) FGuarded(pri Priority, msg func() string) {
+	v.mu.Lock()
+	var err error
+	if v.priority().Enables(pri) {
+		err = v.output(time.Now(), 2, pri, msg())
+	}
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// Audit per Auditor.  The message is rendered with AuditLabel in place of
+// the priority indicator, even if the priority prefix is disabled.  If an
+// audit sink has been set with SetAuditSink the message is passed to it
+// instead.
+func (v *LogLogger) Audit(format string, args ...interface{}) {
+	v.mu.Lock()
+	sink := v.auditSink
+	mf := v.formatting()
+	v.mu.Unlock()
+	if sink != nil {
+		Audit(sink, format, args...)
+		return
+	}
+	s := mf.sprintf(format, args)
+	v.mu.Lock()
+	err := v.output(time.Now(), 2, auditPriority, s)
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// SetAuditSink routes audit messages to sink rather than to the output of
+// v, so compliance records can be kept separately from operational logs.
+// Passing nil restores the default.
+func (v *LogLogger) SetAuditSink(sink ImmutableLogger) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.auditSink = sink
+	return v
+}
+
+// FF per FieldLogger.  Fields are rendered after the message as key=value
+// text, in order of key, using the separator and quoting style configured
+// with SetFieldSeparator and SetFieldQuoting.  Fields excluded by the policy
+// set with SetFieldPolicy are omitted.
+func (v *LogLogger) FF(pri Priority, fields Fields, format string, args ...interface{}) {
+	v.mu.Lock()
+	enabled := v.priority().Enables(pri)
+	mf := v.formatting()
+	filter, sensitive, ffmt := v.fieldFilter, v.sensitive, v.fieldFmt
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	if sensitive == nil {
+		sensitive = defaultSensitiveKeys
+	}
+	fields = sensitive.mask(filter.apply(pri, fields))
+	buf := ffmt.appendFields([]byte(mf.sprintf(format, args)), fields)
+	v.mu.Lock()
+	err := v.output(time.Now(), 2, pri, string(buf))
+	v.mu.Unlock()
+	v.reportError(err)
+}
+
+// SetSensitiveKeys specifies the keys of fields whose values FF renders as
+// MaskedValue, so secrets passed as fields never reach the output.  Keys
+// are matched without regard to case.  The default is DefaultSensitiveKeys;
+// invoking SetSensitiveKeys with no keys disables masking.
+func (v *LogLogger) SetSensitiveKeys(keys ...string) *LogLogger {
+	sk := makeSensitiveKeys(keys)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.sensitive = sk
+	return v
+}
+
+// SetFieldSeparator specifies the text that precedes each field rendered by
+// FF.  Passing an empty string restores DefaultFieldSeparator.
+func (v *LogLogger) SetFieldSeparator(sep string) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fieldFmt.sep = sep
+	return v
+}
+
+// SetFieldQuoting selects how FF protects field values that cannot be
+// rendered as-is.  The default is QuoteAsNeeded.
+func (v *LogLogger) SetFieldQuoting(quote FieldQuoting) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fieldFmt.quote = quote
+	return v
+}
+
+// SetFieldPolicy restricts the fields rendered by FF according to policy.
+// Passing nil restores the default of rendering all fields.  The policy is
+// copied.
+func (v *LogLogger) SetFieldPolicy(policy FieldPolicy) *LogLogger {
+	ff := makeFieldFilter(policy)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fieldFilter = ff
+	return v
+}
+
+// SetErrorSink specifies a logger that receives an Error message describing
+// any failure to write a message to the output.  By default such failures
+// are silently dropped.  Passing nil restores the default.
+//
+// The sink must not be v, and must not be configured to report its own
+// failures back to v.
+func (v *LogLogger) SetErrorSink(sink ImmutableLogger) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.errSink = sink
+	return v
+}
+
+// reportError forwards a non-nil err to the error sink, if there is one.
+// The caller must not hold v.mu.
+func (v *LogLogger) reportError(err error) {
+	if err == nil {
+		return
 	}
+	v.mu.Lock()
+	sink := v.errSink
+	v.mu.Unlock()
+	if sink != nil && sink != ImmutableLogger(v) {
+		sink.F(Error, "logwrap: write failed: %v", err)
+	}
+}
+
+// output renders a message the way log.Logger would, but using t as the
+// timestamp, and writes it with a single call to the log.Logger output.  The
+// caller must hold v.mu.  calldepth counts the frames between output and the
+// code that should be identified by log.Lshortfile or log.Llongfile.  Any
+// error from the write is returned.
+func (v *LogLogger) output(t time.Time, calldepth int, pri Priority, s string) error {
+	var buf []byte
+	if v.progress {
+		// Terminate the progress line so it is not overwritten.
+		buf = append(buf, '\n')
+		v.progress = false
+	}
+	buf = v.render(buf, t, calldepth+1, pri, s)
+	if len(s) == 0 || s[len(s)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	_, err := v.lgr.Writer().Write(buf)
+	return err
+}
+
+// render appends to buf the message as formatted by output, without a
+// trailing newline.  The caller must hold v.mu.  calldepth counts the frames
+// between render and the code that produced the message.
+func (v *LogLogger) render(buf []byte, t time.Time, calldepth int, pri Priority, s string) []byte {
+	flags := v.lgr.Flags()
+	if pf, ok := v.priFlags[pri]; ok {
+		flags = pf&^log.Lmsgprefix | flags&log.Lmsgprefix
+	}
+	prefix := v.cachedPrefix()
+	if flags&log.Lmsgprefix == 0 {
+		buf = append(buf, prefix...)
+	}
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		if flags&log.LUTC != 0 {
+			t = t.UTC()
+		}
+		if flags&log.Ldate != 0 {
+			buf = t.AppendFormat(buf, "2006/01/02 ")
+		}
+		if flags&log.Lmicroseconds != 0 {
+			buf = t.AppendFormat(buf, "15:04:05.000000 ")
+		} else if flags&log.Ltime != 0 {
+			buf = t.AppendFormat(buf, "15:04:05 ")
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		_, file, line, ok := runtime.Caller(calldepth)
+		if !ok {
+			file = "???"
+			line = 0
+		} else if flags&log.Lshortfile != 0 {
+			file = filepath.Base(file)
+		}
+		buf = append(buf, file...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(line), 10)
+		buf = append(buf, ": "...)
+	}
+	if flags&log.Lmsgprefix != 0 {
+		buf = append(buf, prefix...)
+	}
+	if !v.noPriPfx || pri == auditPriority {
+		buf = append(buf, v.cachedLabel(pri)...)
+	}
+	if v.stackDepth {
+		buf = appendStackDepth(buf, calldepth+1)
+	}
+	return append(buf, s...)
+}
+
+// MaxStackDepth is the largest call stack depth measured for the indicator
+// enabled by SetStackDepth.  Deeper stacks are rendered as "(d=256+)".
+const MaxStackDepth = 256
+
+// SetStackDepth enables an indicator of the depth of the call stack of the
+// goroutine that logs each message, rendered like "(d=37) " before the
+// message.  Comparing depths across messages can reveal runaway recursion.
+//
+// Measuring the depth requires walking the stack on every emitted message,
+// which is much more expensive than formatting a typical message, so this
+// should be enabled only while diagnosing a problem.
+func (v *LogLogger) SetStackDepth(enabled bool) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.stackDepth = enabled
+	return v
+}
+
+// appendStackDepth appends the depth indicator for the stack above the frame
+// identified by skip as used by runtime.Callers.
+func appendStackDepth(buf []byte, skip int) []byte {
+	var pcs [MaxStackDepth + 1]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	buf = append(buf, "(d="...)
+	if n > MaxStackDepth {
+		buf = strconv.AppendInt(buf, MaxStackDepth, 10)
+		buf = append(buf, '+')
+	} else {
+		buf = strconv.AppendInt(buf, int64(n), 10)
+	}
+	return append(buf, ") "...)
 }
 
 // SetId per Logger.  The provided id becomes the log.Logger prefix,
 // and log.Lmsgprefix is applied to the flags.
 func (v *LogLogger) SetId(id string) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	v.lgr.SetFlags(v.lgr.Flags() | log.Lmsgprefix)
 	v.lgr.SetPrefix(id)
 	return v
 }
 
+// AppendId per IdAppender.  The separator is "." unless changed with
+// SetIdSeparator.
+func (v *LogLogger) AppendId(id string) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	sep := v.idSep
+	if sep == "" {
+		sep = DefaultIdSeparator
+	}
+	v.lgr.SetFlags(v.lgr.Flags() | log.Lmsgprefix)
+	v.lgr.SetPrefix(composeId(v.lgr.Prefix(), sep, id))
+	return v
+}
+
+// SetIdSeparator specifies the text placed between the existing identifier
+// and the identifier passed to AppendId.  An empty sep selects
+// DefaultIdSeparator.
+func (v *LogLogger) SetIdSeparator(sep string) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.idSep = sep
+	return v
+}
+
+// SetTag specifies text that identifies the process, such as a cluster
+// name, to be rendered on every line independent of the identifier.  The
+// tag is followed by a space and placed immediately before the identifier,
+// which follows the date and time when an identifier has been set with
+// SetId, e.g. "2006/01/02 15:04:05 cluster-a svc.db [W] message".  SetId
+// and AppendId do not affect the tag.  An empty tag removes it.
+func (v *LogLogger) SetTag(tag string) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.tag = tag
+	v.invalidatePrefix()
+	return v
+}
+
 // SetPriority per Logger.
 func (v *LogLogger) SetPriority(pri Priority) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.setPriority(pri)
+	v.revertAt = time.Time{}
+	return v
+}
+
+// SetPriorityNotices controls whether changes to the priority are recorded
+// in the log.  When enabled, each change made through SetPriority,
+// SetPriorityUntil, or the revert of a temporary priority emits a message
+// "log priority changed from X to Y" at Notice priority.  The message is
+// emitted even if the old and new priorities would filter Notice, so the
+// log shows exactly when verbosity changed.  This is disabled by default.
+func (v *LogLogger) SetPriorityNotices(enabled bool) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.priNotices = enabled
+	return v
+}
+
+// setPriority changes the priority, emitting a notice if that's enabled and
+// the priority changed.  The message is written directly so this cannot
+// recurse.  The caller must hold v.mu.
+func (v *LogLogger) setPriority(pri Priority) {
+	old := v.pri
 	v.pri = pri
+	if v.priNotices && old != pri {
+		_ = v.output(time.Now(), 3, Notice, fmt.Sprintf("log priority changed from %s to %s", old, pri))
+	}
+}
+
+// SetPriorityUntil changes the priority to pri for duration d, after which
+// the priority reverts to the value it had before the change.  This guards
+// against verbose priorities enabled for diagnosis being left in place.
+//
+// The revert happens lazily: the deadline is checked on the next call to
+// Priority, F, or FAt at or after it expires.  Invoking SetPriorityUntil
+// again before the deadline changes the temporary priority and deadline but
+// retains the original revert priority.  Invoking SetPriority cancels the
+// revert.
+func (v *LogLogger) SetPriorityUntil(pri Priority, d time.Duration) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.revertAt.IsZero() {
+		v.revertPri = v.pri
+	}
+	v.setPriority(pri)
+	v.revertAt = time.Now().Add(d)
+	return v
+}
+
+// priority returns the priority in effect, first applying any revert that
+// has become due.  The caller must hold v.mu.
+func (v *LogLogger) priority() Priority {
+	if !v.revertAt.IsZero() && !time.Now().Before(v.revertAt) {
+		v.revertAt = time.Time{}
+		v.setPriority(v.revertPri)
+	}
+	return v.pri
+}
+
+// SetLabelStyle selects how the priority indicator is rendered.
+func (v *LogLogger) SetLabelStyle(style LabelStyle) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.label = style
+	v.invalidatePrefix()
+	return v
+}
+
+// SetLabelEmoji replaces the symbols rendered by the LabelEmoji style.
+// Each symbol is written as given, so it may be any UTF-8 text, including
+// sequences of several runes.  The map is copied; changing it after the
+// call has no effect.  Passing nil restores DefaultLabelEmoji.  This does
+// not select the LabelEmoji style.
+func (v *LogLogger) SetLabelEmoji(emoji map[Priority]string) *LogLogger {
+	var m map[Priority]string
+	if emoji != nil {
+		m = make(map[Priority]string, len(emoji))
+		for pri, e := range emoji {
+			m[pri] = e
+		}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.emoji = m
+	v.invalidatePrefix()
+	return v
+}
+
+// prefixCache holds text rendered at the start of every message that
+// depends only on the logger configuration, so it need not be recomposed
+// for each message.  The cache is discarded by the setters that change the
+// configuration, and when the log.Logger prefix or the decision to color it
+// changes.
+type prefixCache struct {
+	valid   bool
+	id      string
+	colored bool
+	// prefix is the tag and the possibly colored identifier.
+	prefix []byte
+	// labels holds the bracketed priority indicator with its trailing
+	// space for each priority, or nil if it has not been rendered.
+	labels [Debug + 1][]byte
+}
+
+// invalidatePrefix discards the rendered prefixes.  The caller must hold
+// v.mu.
+func (v *LogLogger) invalidatePrefix() {
+	v.pfxCache = prefixCache{}
+}
+
+// cachedPrefix returns the rendered tag and identifier, recomposing it only if
+// the cache is stale.  The caller must hold v.mu.
+func (v *LogLogger) cachedPrefix() []byte {
+	pc := &v.pfxCache
+	id := v.lgr.Prefix()
+	colored := id != "" && v.colorize(v.idColor)
+	if !pc.valid || pc.id != id || pc.colored != colored {
+		*pc = prefixCache{
+			valid:   true,
+			id:      id,
+			colored: colored,
+		}
+		var buf []byte
+		if v.tag != "" {
+			buf = append(buf, v.tag...)
+			buf = append(buf, ' ')
+		}
+		if colored {
+			buf = append(buf, idColor(id)...)
+			buf = append(buf, id...)
+			buf = append(buf, colorReset...)
+		} else {
+			buf = append(buf, id...)
+		}
+		pc.prefix = buf
+	}
+	return pc.prefix
+}
+
+// cachedLabel returns the bracketed priority indicator for pri with its trailing
+// space, rendering it only if the cache is stale.  The caller must hold
+// v.mu and must have invoked cachedPrefix to validate the cache.
+func (v *LogLogger) cachedLabel(pri Priority) []byte {
+	if pri < Emerg || pri > Debug {
+		buf := append([]byte{'['}, v.appendLabel(nil, pri)...)
+		return append(buf, "] "...)
+	}
+	lp := &v.pfxCache.labels[pri]
+	if *lp == nil {
+		buf := append([]byte{'['}, v.appendLabel(nil, pri)...)
+		*lp = append(buf, "] "...)
+	}
+	return *lp
+}
+
+// appendLabel appends the priority indicator for pri in the configured style.
+func (v *LogLogger) appendLabel(buf []byte, pri Priority) []byte {
+	start := len(buf)
+	if pri == auditPriority {
+		return append(buf, AuditLabel...)
+	}
+	switch v.label {
+	case LabelLevel:
+		buf = strconv.AppendInt(buf, int64(pri.Level()), 10)
+	case LabelLevelLetter:
+		buf = strconv.AppendInt(buf, int64(pri.Level()), 10)
+		buf = append(buf, priMap[pri]...)
+	case LabelName:
+		buf = append(buf, pri.String()...)
+	case LabelEmoji:
+		emoji := v.emoji
+		if emoji == nil {
+			emoji = DefaultLabelEmoji
+		}
+		if e, ok := emoji[pri]; ok {
+			buf = append(buf, e...)
+		} else {
+			buf = append(buf, priMap[pri]...)
+		}
+	default:
+		buf = append(buf, priMap[pri]...)
+	}
+	if v.padLabel {
+		for n := labelWidth(v.label) - (len(buf) - start); n > 0; n-- {
+			buf = append(buf, ' ')
+		}
+	}
+	return buf
+}
+
+// labelWidth returns the length of the longest label rendered in style.
+func labelWidth(style LabelStyle) int {
+	if style != LabelName {
+		// Every other style has fixed width as long as Level is a
+		// single digit.  Emoji are not padded since their display
+		// width is unrelated to their encoded length.
+		return 0
+	}
+	rv := 0
+	for pri := Emerg; pri <= Debug; pri++ {
+		if n := len(pri.String()); n > rv {
+			rv = n
+		}
+	}
+	return rv
+}
+
+// SetLabelPadding controls whether the priority indicator is padded with
+// trailing spaces to a fixed width, so messages start in the same column
+// regardless of priority.  This matters only for LabelName, where labels
+// range from "[Info]" to "[Warning]"; the other styles already have a fixed
+// width.
+func (v *LogLogger) SetLabelPadding(pad bool) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.padLabel = pad
+	v.invalidatePrefix()
+	return v
+}
+
+// SetArgLimit bounds the length of the rendered form of each argument to a
+// message to maxBytes bytes.  Longer renderings are cut, without splitting
+// a UTF-8 encoded rune, and followed by ArgTruncated.  This protects
+// against giant messages produced by accidentally logging a large value.
+// The format string itself is not limited, nor are arguments consumed by a
+// '*' width or precision.  If the format uses explicit argument indexes, %T
+// reports the type of an internal wrapper.  A maxBytes that is not positive
+// removes the limit, which is the default.
+func (v *LogLogger) SetArgLimit(maxBytes int) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.argLimit = maxBytes
+	return v
+}
+
+// SetFormatFallback controls whether a message whose arguments do not match
+// its format string in number or type is rendered as the unformatted
+// format string followed by the arguments, rather than with the
+// %!d(MISSING), %!(EXTRA ...), and %!d(string=...) markers produced by
+// fmt.  This ensures a malformed call still conveys the information its
+// author intended.  See FormatFallbackArgs for the form.  The fallback is
+// disabled by default.
+func (v *LogLogger) SetFormatFallback(enable bool) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fallback = enable
+	return v
+}
+
+// msgFormat holds the configuration that controls how a message is
+// formatted from its arguments.  It is captured while holding v.mu so that
+// the arguments can be formatted without it.
+type msgFormat struct {
+	argLimit int
+	fallback bool
+}
+
+// formatting returns the current message formatting configuration.  The
+// caller must hold v.mu.
+func (v *LogLogger) formatting() msgFormat {
+	return msgFormat{
+		argLimit: v.argLimit,
+		fallback: v.fallback,
+	}
+}
+
+// sprintf formats the message, applying the argument limit and format
+// fallback.
+func (f msgFormat) sprintf(format string, args []interface{}) string {
+	if f.argLimit > 0 && len(args) != 0 {
+		args = limitArgs(format, args, f.argLimit)
+	}
+	s := fmt.Sprintf(format, args...)
+	if f.fallback && formatMismatch(format, args, s) {
+		s = formatFallback(format, args)
+	}
+	return s
+}
+
+// SetPriorityFlags specifies log.Logger flags that are used in place of the
+// flags of the underlying log.Logger for messages emitted at pri.  For
+// example log.LstdFlags|log.Lshortfile can be used for Debug to identify
+// the source of diagnostic messages while messages at other priorities
+// stay uncluttered.  A negative value for flags removes the override.
+//
+// The log.Lmsgprefix bit is always taken from the log.Logger flags, so the
+// identifier assigned by SetId is placed the same way at all priorities.
+func (v *LogLogger) SetPriorityFlags(pri Priority, flags int) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if flags < 0 {
+		delete(v.priFlags, pri)
+		return v
+	}
+	if v.priFlags == nil {
+		v.priFlags = make(map[Priority]int)
+	}
+	v.priFlags[pri] = flags
+	return v
+}
+
+// SetIdColor controls whether the identifier is rendered in a color derived
+// from a hash of its text, which makes it easier to distinguish the output
+// of different components in interleaved logs.  With ColorAuto the color is
+// used only when the log.Logger output is an *os.File that is a terminal.
+func (v *LogLogger) SetIdColor(mode ColorMode) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.idColor = mode
+	return v
+}
+
+// colorize returns true if output should be colored under mode.  The caller
+// must hold v.mu.
+func (v *LogLogger) colorize(mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		return v.tty.check(v.lgr.Writer())
+	}
+	return false
+}
+
+// SetPriorityPrefix controls whether messages are prefixed with the priority
+// indicator.  This is enabled by default; disable it when the output
+// already conveys severity out-of-band, e.g. through syslog framing.
+func (v *LogLogger) SetPriorityPrefix(enabled bool) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.noPriPfx = !enabled
+	v.invalidatePrefix()
+	return v
+}
+
+// SetOutput sets the destination of messages emitted by v.  This is
+// equivalent to Instance().SetOutput(w) but is coordinated with the
+// LogLogger mutex, so it does not affect a message being emitted
+// concurrently.
+func (v *LogLogger) SetOutput(w io.Writer) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.lgr.SetOutput(w)
 	return v
 }
 
+// Output returns the writer to which v currently emits messages, allowing
+// tools to report the destination and tests to confirm redirection.  The
+// writer is obtained from the underlying log.Logger, so it reflects changes
+// made through SetOutput, CaptureOutput, or Instance().SetOutput.
+func (v *LogLogger) Output() io.Writer {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.lgr.Writer()
+}
+
+// CaptureOutput diverts the output of v to a buffer while fn runs, then
+// restores the previous output and returns the text that was written.  This
+// supports returning the log of an operation to the caller that requested
+// it, e.g. in an RPC response.
+//
+// Messages emitted through v by any goroutine while fn runs are captured.
+// Concurrent calls to CaptureOutput are serialized, so nested calls from
+// within fn deadlock.  The output is restored even if fn panics.
+func (v *LogLogger) CaptureOutput(fn func()) (captured string) {
+	v.capMu.Lock()
+	defer v.capMu.Unlock()
+	var buf bytes.Buffer
+	v.mu.Lock()
+	prev := v.lgr.Writer()
+	v.lgr.SetOutput(&buf)
+	v.mu.Unlock()
+	defer func() {
+		v.mu.Lock()
+		v.lgr.SetOutput(prev)
+		captured = buf.String()
+		v.mu.Unlock()
+	}()
+	fn()
+	return
+}
+
 // Instance provides access to the underlying log.Logger to configure things
-// that are not part of the logwrap API.
+// that are not part of the logwrap API.  Changes made through the instance are
+// not coordinated with the LogLogger mutex.
 func (v *LogLogger) Instance() *log.Logger {
 	return v.lgr
 }
@@ -372,6 +1594,32 @@ type chanLogger struct {
 	ech chan<- Emitter
 	pfx string
 	lgr ImmutableLogger
+	st  *chanState
+	// ctx, if not nil, abandons sends once it is done.
+	ctx context.Context
+	// nonblock drops messages rather than wait for room in the channel.
+	nonblock bool
+	// syncPri, if set, is the least severe priority of messages that are
+	// emitted synchronously as selected by syncMode.
+	syncPri  Priority
+	syncMode SyncMode
+}
+
+// chanState holds configuration and statistics shared by all channel loggers
+// that use the same channel.
+type chanState struct {
+	// dropped counts messages that were not sent.  Access it atomically.
+	// It must stay the first field so it is 64-bit aligned on 32-bit
+	// platforms, as required by sync/atomic.
+	dropped uint64
+
+	mu      sync.RWMutex
+	latency func(time.Duration)
+	onDrop  func(pri Priority, format string)
+	// syncEmit is set once a SyncChanLogger has been derived, after
+	// which emission holds emitMu.
+	syncEmit bool
+	emitMu   sync.Mutex
 }
 
 // Emitter is implemented by encapsulated log messages, e.g. those sent by a
@@ -382,6 +1630,21 @@ type Emitter interface {
 	Emit()
 }
 
+// MessageEmitter is implemented by encapsulated log messages that can
+// describe themselves, allowing a consumer to filter or reroute messages
+// before, or instead of, emitting them.  The Emitters sent by channel
+// loggers implement MessageEmitter.
+type MessageEmitter interface {
+	Emitter
+
+	// Priority returns the priority of the message.
+	Priority() Priority
+
+	// Message returns the text of the message as it would be passed to
+	// the underlying logger, formatting it on each call.
+	Message() string
+}
+
 // MakeChanLogger constructs a channel and a ImmutableLogger such that
 // messages emitted by the ImmutableLogger are processed and emitted via lgr
 // in its native context.
@@ -403,9 +1666,36 @@ func MakeChanLogger(lgr ImmutableLogger, cap int) (ImmutableLogger, <-chan Emitt
 	return &chanLogger{
 		ech: ech,
 		lgr: lgr,
+		st:  &chanState{},
 	}, ech
 }
 
+// InitialChanCap is the channel capacity used by MakeChanLoggerDefault until
+// SetDefaultChanCap is invoked.
+const InitialChanCap = 64
+
+// defaultChanCap is the capacity used by MakeChanLoggerDefault.  Access it
+// atomically.
+var defaultChanCap int64 = InitialChanCap
+
+// SetDefaultChanCap sets the channel capacity used by subsequent calls to
+// MakeChanLoggerDefault.  This allows an application to tune log buffering
+// in one place.  Values of n less than 1 are replaced by 1.  Existing
+// channel loggers are not affected.  This function is safe for concurrent
+// use.
+func SetDefaultChanCap(n int) {
+	if n < 1 {
+		n = 1
+	}
+	atomic.StoreInt64(&defaultChanCap, int64(n))
+}
+
+// MakeChanLoggerDefault is MakeChanLogger using the capacity configured by
+// SetDefaultChanCap.
+func MakeChanLoggerDefault(lgr ImmutableLogger) (ImmutableLogger, <-chan Emitter) {
+	return MakeChanLogger(lgr, int(atomic.LoadInt64(&defaultChanCap)))
+}
+
 // PrefixedChanLogger constructs a new ImmutableLogger that uses the same
 // channel as lgr, but prepends pfx to all format strings passed to the
 // returned logger's F function.  This simplifies ensuring that messages can
@@ -424,6 +1714,313 @@ func PrefixedChanLogger(lgr ImmutableLogger, pfx string) ImmutableLogger {
 	return rv
 }
 
+// PendingFraction returns the fraction of the capacity of the channel used
+// by lgr that is occupied by messages that have not yet been received.
+// Producers can use this to shed non-critical logging before the channel
+// fills and F blocks.
+//
+// The returned value is zero if lgr was not constructed by MakeChanLogger or
+// PrefixedChanLogger.  This function is safe for concurrent use with F.
+func PendingFraction(lgr ImmutableLogger) float64 {
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		return float64(len(cl.ech)) / float64(cap(cl.ech))
+	}
+	return 0
+}
+
+// SetLatencyObserver registers fn to be invoked from Emit with the time that
+// each message spent between being submitted to F and being emitted.  This
+// measures consumer lag, e.g. to record a histogram used to size the channel
+// capacity.  Passing nil removes the observer.
+//
+// The observer applies to all loggers that use the same channel as lgr.  It
+// is invoked in the goroutine that invokes Emit, and only for messages
+// submitted after it was registered.  This function has no effect if lgr was
+// not constructed by MakeChanLogger or PrefixedChanLogger.
+func SetLatencyObserver(lgr ImmutableLogger, fn func(time.Duration)) {
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		cl.st.mu.Lock()
+		defer cl.st.mu.Unlock()
+		cl.st.latency = fn
+	}
+}
+
+// SetDropHandler registers fn to be invoked with the priority and format
+// string of each message that a channel logger drops, e.g. because the
+// channel of a NonBlockingChanLogger is full or the context of a
+// ContextChanLogger is done.  This allows drops to be counted by priority
+// or sampled into a separate sink.  Passing nil removes the handler.
+//
+// The handler applies to all loggers that use the same channel as lgr.  It
+// is invoked synchronously in the goroutine that invoked F, after the drop
+// has been counted, so it must not block: a handler that waits on the
+// consumer of the channel defeats the purpose of dropping and may
+// deadlock.  The message arguments are not provided, as formatting them
+// would add the cost that dropping is meant to avoid.  This function has no
+// effect if lgr was not constructed by MakeChanLogger or one of the
+// functions that derive channel loggers.
+func SetDropHandler(lgr ImmutableLogger, fn func(pri Priority, format string)) {
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		cl.st.mu.Lock()
+		defer cl.st.mu.Unlock()
+		cl.st.onDrop = fn
+	}
+}
+
+// send completes e with state captured at submission and transmits it.
+func (v *chanLogger) send(e *emittable) {
+	v.st.mu.RLock()
+	e.latency = v.st.latency
+	onDrop := v.st.onDrop
+	if v.st.syncEmit {
+		e.mu = &v.st.emitMu
+	}
+	v.st.mu.RUnlock()
+	if v.syncPri.IsSet() && v.syncPri.Enables(e.Priority()) {
+		e.emit()
+		if v.syncMode == SyncOnly {
+			if e.done != nil {
+				close(e.done)
+			}
+			return
+		}
+	}
+	if e.latency != nil {
+		e.enq = time.Now()
+	}
+	switch {
+	case v.ctx == nil && !v.nonblock:
+		v.ech <- e
+		return
+	case v.ctx != nil && v.ctx.Err() != nil:
+	case v.nonblock:
+		select {
+		case v.ech <- e:
+			return
+		default:
+		}
+	default:
+		select {
+		case v.ech <- e:
+			return
+		case <-v.ctx.Done():
+		}
+	}
+	atomic.AddUint64(&v.st.dropped, 1)
+	if onDrop != nil {
+		onDrop(e.pri, e.fmt)
+	}
+	if e.done != nil {
+		close(e.done)
+	}
+}
+
+// FDone submits a message to lgr as with F and returns a channel that is
+// closed once the message has been emitted.  This allows tests to wait for
+// a specific message to reach the underlying logger before checking its
+// output, rather than sleeping and hoping the consumer has caught up.
+//
+// Each call allocates a channel that the caller must retain until it
+// closes, so FDone is meant for tests and the occasional message whose
+// emission must be confirmed, not for routine logging.  The channel is also
+// closed if the message is dropped, e.g. because the context of a
+// ContextChanLogger is done.  If lgr was not constructed by MakeChanLogger
+// or one of the functions that derive channel loggers the message is passed
+// to lgr.F and the returned channel is already closed.
+func FDone(lgr ImmutableLogger, pri Priority, format string, args ...interface{}) <-chan struct{} {
+	done := make(chan struct{})
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		cl.send(&emittable{
+			lgr:  cl.lgr,
+			pri:  pri,
+			pfx:  cl.pfx,
+			fmt:  cl.pfx + format,
+			args: args,
+			done: done,
+		})
+		return done
+	}
+	if lgr != nil {
+		lgr.F(pri, format, args...)
+	}
+	close(done)
+	return done
+}
+
+// ContextChanLogger constructs a new ImmutableLogger that uses the same
+// channel and prefix as lgr, but abandons messages once ctx is done.  If ctx
+// is done when F is invoked, or becomes done while F is blocked because the
+// channel is full, F returns immediately and the message is dropped and
+// counted.  This prevents goroutines serving a cancelled request from
+// blocking shutdown while trying to log.
+//
+// The returned ImmutableLogger is nil if lgr was not constructed by
+// MakeChanLogger, PrefixedChanLogger, or ContextChanLogger.  Calls to the F
+// method of the nil logger will silently drop all messages submitted to it.
+func ContextChanLogger(lgr ImmutableLogger, ctx context.Context) ImmutableLogger {
+	var rv *chanLogger
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		cl2 := *cl
+		cl2.ctx = ctx
+		rv = &cl2
+	}
+	return rv
+}
+
+// NonBlockingChanLogger constructs a new ImmutableLogger that uses the same
+// channel, prefix, and context as lgr, but never waits for room in the
+// channel.  If the channel is full when F is invoked the message is dropped
+// and counted, and passed to any handler registered with SetDropHandler.
+// This suits producers that must not be delayed by a slow consumer, at the
+// cost of losing messages during bursts.
+//
+// The returned ImmutableLogger is nil if lgr was not constructed by
+// MakeChanLogger or one of the functions that derive channel loggers.
+// Calls to the F method of the nil logger will silently drop all messages
+// submitted to it.
+func NonBlockingChanLogger(lgr ImmutableLogger) ImmutableLogger {
+	var rv *chanLogger
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		cl2 := *cl
+		cl2.nonblock = true
+		rv = &cl2
+	}
+	return rv
+}
+
+// SyncMode selects whether messages that SyncChanLogger emits synchronously
+// are also sent through the channel.
+type SyncMode int
+
+const (
+	// SyncOnly emits qualifying messages synchronously instead of sending
+	// them through the channel.
+	SyncOnly SyncMode = iota
+	// SyncAndQueue emits qualifying messages synchronously and also sends
+	// them through the channel.  Unless the consumer reroutes them, e.g.
+	// by inspecting them as MessageEmitter, they appear twice in the
+	// output of the underlying logger.
+	SyncAndQueue
+)
+
+// SyncChanLogger constructs a new ImmutableLogger that uses the same
+// channel, prefix, and context as lgr, but emits messages at pri or more
+// severe synchronously: F passes them directly to the underlying logger
+// before it returns, so they are not lost in the channel if the process
+// crashes before the consumer drains it.  mode selects whether they are
+// also sent through the channel.  This costs synchronization on what should
+// be rare lines, so pri is normally Crit or Emerg.
+//
+// The synchronous path invokes the underlying logger from the goroutine
+// that invoked F, concurrently with the goroutine that invokes Emit.  To
+// preserve the guarantee that the underlying logger need not be safe for
+// concurrent use, once SyncChanLogger has been invoked every emission of a
+// message from the channel, synchronous or through Emit, holds a mutex
+// shared by all loggers that use the channel.  This protects the underlying
+// logger only if it is used exclusively through those loggers.  Messages
+// submitted before the first call are emitted without the mutex, so derive
+// the logger before starting the goroutines that use it.  A synchronous F
+// waits for any message being emitted by the consumer, and for the
+// underlying logger to complete its output.
+//
+// The returned ImmutableLogger is nil if lgr was not constructed by
+// MakeChanLogger or one of the functions that derive channel loggers.
+// Calls to the F method of the nil logger will silently drop all messages
+// submitted to it.
+func SyncChanLogger(lgr ImmutableLogger, pri Priority, mode SyncMode) ImmutableLogger {
+	var rv *chanLogger
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		cl.st.mu.Lock()
+		cl.st.syncEmit = true
+		cl.st.mu.Unlock()
+		cl2 := *cl
+		cl2.syncPri = pri
+		cl2.syncMode = mode
+		rv = &cl2
+	}
+	return rv
+}
+
+// broadcastLogger submits each message to several channel loggers.
+type broadcastLogger struct {
+	lgrs []*chanLogger
+}
+
+// BroadcastChanLogger constructs a new ImmutableLogger that submits each
+// message passed to its F function to every one of loggers, so that several
+// consumers, e.g. one writing to a file and another feeding a live viewer,
+// each receive the full stream.  The message is submitted to loggers in the
+// order they are given, each through its own channel, and each consumer
+// drains its channel independently.
+//
+// Backpressure applies per logger: F returns only after the message has
+// been submitted to every channel, so a channel that is full blocks F and
+// delays submission to the loggers that follow it.  A logger constructed by
+// ContextChanLogger instead drops the message once its context is done,
+// counting it in DroppedMessages for that channel, without affecting the
+// other loggers.  Place loggers for consumers that may fall behind last, or
+// bind them to a context, to avoid stalling faster consumers.
+//
+// Priority returns the least restrictive priority of the loggers.  Loggers
+// that were not constructed by MakeChanLogger or one of the functions that
+// derive channel loggers are ignored.  The returned ImmutableLogger is nil
+// if none of loggers is a channel logger; calls to the F method of the nil
+// logger will silently drop all messages submitted to it.
+func BroadcastChanLogger(loggers ...ImmutableLogger) ImmutableLogger {
+	var rv *broadcastLogger
+	for _, lgr := range loggers {
+		if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+			if rv == nil {
+				rv = &broadcastLogger{}
+			}
+			rv.lgrs = append(rv.lgrs, cl)
+		}
+	}
+	return rv
+}
+
+// Priority per ImmutableLogger.
+func (v *broadcastLogger) Priority() Priority {
+	pri := v.lgrs[0].Priority()
+	for _, cl := range v.lgrs[1:] {
+		if p := cl.Priority(); p > pri {
+			pri = p
+		}
+	}
+	return pri
+}
+
+// F per ImmutableLogger.
+func (v *broadcastLogger) F(pri Priority, format string, args ...interface{}) {
+	if v != nil {
+		for _, cl := range v.lgrs {
+			cl.F(pri, format, args...)
+		}
+	}
+}
+
+// Audit per Auditor.
+func (v *broadcastLogger) Audit(format string, args ...interface{}) {
+	if v != nil {
+		for _, cl := range v.lgrs {
+			cl.Audit(format, args...)
+		}
+	}
+}
+
+// DroppedMessages returns the number of messages that were not sent to the
+// channel used by lgr, e.g. because the channel of a NonBlockingChanLogger
+// was full or the context of a ContextChanLogger was done.  The count is
+// shared by all loggers that use the same channel.  It is zero if lgr was
+// not constructed by MakeChanLogger or one of the functions that derive
+// channel loggers.
+func DroppedMessages(lgr ImmutableLogger) uint64 {
+	if cl, ok := lgr.(*chanLogger); ok && cl != nil {
+		return atomic.LoadUint64(&cl.st.dropped)
+	}
+	return 0
+}
+
 // Priority per ImmutableLogger.
 func (v *chanLogger) Priority() Priority {
 	return v.lgr.Priority()
@@ -432,24 +2029,158 @@ func (v *chanLogger) Priority() Priority {
 // F per ImmutableLogger.
 func (v *chanLogger) F(pri Priority, format string, args ...interface{}) {
 	if v != nil {
-		v.ech <- &emittable{
+		v.send(&emittable{
 			lgr:  v.lgr,
 			pri:  pri,
+			pfx:  v.pfx,
 			fmt:  v.pfx + format,
 			args: args,
+		})
+	}
+}
+
+// Audit per Auditor.  The message is emitted with Audit on the underlying
+// logger.  It is submitted at Emerg, which is the priority that a consumer
+// inspecting it as MessageEmitter observes.
+func (v *chanLogger) Audit(format string, args ...interface{}) {
+	if v != nil {
+		v.send(&emittable{
+			lgr:   v.lgr,
+			pri:   Emerg,
+			pfx:   v.pfx,
+			fmt:   v.pfx + format,
+			args:  args,
+			audit: true,
+		})
+	}
+}
+
+// FRequest per RequestLogger.  The request identifier is captured with the
+// message and rendered after the logger prefix when the message is emitted.
+func (v *chanLogger) FRequest(rid string, pri Priority, format string, args ...interface{}) {
+	if v != nil {
+		if rid == "" {
+			// Emitted as with F, so the prefix is part of the format.
+			format = v.pfx + format
 		}
+		v.send(&emittable{
+			lgr:  v.lgr,
+			pri:  pri,
+			pfx:  v.pfx,
+			rid:  rid,
+			fmt:  format,
+			args: args,
+		})
+	}
+}
+
+// FContextValue per ContextValueLogger.  cv is captured with the message and
+// passed to the underlying logger when the message is emitted.
+func (v *chanLogger) FContextValue(cv interface{}, pri Priority, format string, args ...interface{}) {
+	if v != nil {
+		v.send(&emittable{
+			lgr:  v.lgr,
+			pri:  pri,
+			pfx:  v.pfx,
+			cv:   cv,
+			fmt:  v.pfx + format,
+			args: args,
+		})
 	}
 }
 
 // emittable packages the log message parameters with the logger to be used to
 // emit them.  It implements Emitter() to output the message.
+//
+// When rid is empty any logger prefix has already been prepended to fmt, and
+// pfx is retained only to identify the source of the message.
 type emittable struct {
 	lgr  ImmutableLogger
 	pri  Priority
+	pfx  string
+	rid  string
 	fmt  string
 	args []interface{}
+
+	// cv is the context value supplied through FContextValue, if any.
+	cv interface{}
+
+	// audit is set for messages submitted through Audit.
+	audit bool
+
+	// block holds the messages of a Block, which replace the message
+	// described by the other fields, if it is not nil.
+	block []blockMessage
+
+	// latency is invoked with the time since enq when the message is
+	// emitted, if it is not nil.
+	latency func(time.Duration)
+	enq     time.Time
+
+	// done, if not nil, is closed after the message is emitted.
+	done chan struct{}
+
+	// mu, if not nil, is held while the message is emitted.
+	mu *sync.Mutex
+}
+
+// Priority per MessageEmitter.  For a Block this is the most severe
+// priority of its messages.
+func (m *emittable) Priority() Priority {
+	if m.block == nil {
+		return m.pri
+	}
+	pri := unsetPriority
+	for _, bm := range m.block {
+		if !pri.IsSet() || bm.pri < pri {
+			pri = bm.pri
+		}
+	}
+	return pri
+}
+
+// Message per MessageEmitter.  For a Block this is the text of its
+// messages separated by newlines.
+func (m *emittable) Message() string {
+	switch {
+	case m.block != nil:
+		parts := make([]string, len(m.block))
+		for i, bm := range m.block {
+			parts[i] = bm.s
+		}
+		return strings.Join(parts, "\n")
+	case m.rid != "":
+		return fmt.Sprintf("%s[%s] %s", m.pfx, m.rid, fmt.Sprintf(m.fmt, m.args...))
+	}
+	return fmt.Sprintf(m.fmt, m.args...)
 }
 
 func (m *emittable) Emit() {
-	m.lgr.F(m.pri, m.fmt, m.args...)
+	if m.latency != nil {
+		m.latency(time.Since(m.enq))
+	}
+	m.emit()
+	if m.done != nil {
+		close(m.done)
+	}
+}
+
+// emit passes the message to the underlying logger, holding m.mu if it is
+// not nil.
+func (m *emittable) emit() {
+	if m.mu != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+	if m.block != nil {
+		emitBlock(m.lgr, 3, m.block)
+	} else if m.audit {
+		Audit(m.lgr, m.fmt, m.args...)
+	} else if m.cv != nil {
+		FContextValue(m.lgr, m.cv, m.pri, m.fmt, m.args...)
+	} else if m.rid == "" {
+		m.lgr.F(m.pri, m.fmt, m.args...)
+	} else if m.lgr.Priority().Enables(m.pri) {
+		m.lgr.F(m.pri, "%s[%s] %s", m.pfx, m.rid, fmt.Sprintf(m.fmt, m.args...))
+	}
 }
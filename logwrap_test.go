@@ -4,12 +4,23 @@
 package logwrap
 
 import (
+	"bytes"
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
+	"unsafe"
 )
 
 // Run standard verification of expected errors, i.e. that err is an
@@ -30,6 +41,16 @@ func confirmError(t *testing.T, err error, base error, errstr string) {
 	}
 }
 
+// Create a LogLogger at Debug priority that writes to sb without the date
+// and time, so emitted lines can be compared exactly.
+func newTestLogger(sb *strings.Builder) Logger {
+	lgr := LogLogMaker(nil)
+	inst := lgr.(*LogLogger).Instance()
+	inst.SetFlags(0)
+	inst.SetOutput(sb)
+	return lgr.SetPriority(Debug)
+}
+
 func TestLogLogger(t *testing.T) {
 	var sb strings.Builder
 	lgr := LogLogMaker(nil)
@@ -78,6 +99,798 @@ func TestLogLogger(t *testing.T) {
 	sb.Reset()
 }
 
+func TestLogLoggerPriorityPrefix(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lgr.SetId("id ")
+	ll := lgr.(*LogLogger)
+
+	ll.SetPriorityPrefix(false)
+	lgr.F(Warning, "no prefix")
+	if s := sb.String(); s != "id no prefix\n" {
+		t.Errorf("prefix not suppressed: %q", s)
+	}
+	sb.Reset()
+
+	ll.SetPriorityPrefix(true)
+	lgr.F(Warning, "prefix")
+	if s := sb.String(); s != "id [W] prefix\n" {
+		t.Errorf("prefix not restored: %q", s)
+	}
+}
+
+func TestLogLoggerConcurrent(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				lgr.SetPriority(Debug)
+				lgr.F(Info, "g%d %d", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(lines) != 400 {
+		t.Fatalf("wrong line count: %d", len(lines))
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "[I] g") {
+			t.Errorf("garbled line: %q", l)
+		}
+	}
+}
+
+func BenchmarkLogLoggerF(b *testing.B) {
+	lgr := LogLogMaker(nil)
+	lgr.(*LogLogger).Instance().SetOutput(io.Discard)
+	for i := 0; i < b.N; i++ {
+		lgr.F(Warning, "message %d", i)
+	}
+}
+
+func BenchmarkLogLoggerFParallel(b *testing.B) {
+	lgr := LogLogMaker(nil)
+	lgr.(*LogLogger).Instance().SetOutput(io.Discard)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			lgr.F(Warning, "message %d", i)
+			i++
+		}
+	})
+}
+
+func BenchmarkLogLoggerPrefix(b *testing.B) {
+	lgr := LogLogMaker(nil).SetId("svc.db ")
+	ll := lgr.(*LogLogger)
+	ll.SetOutput(io.Discard).SetIdColor(ColorAlways).SetTag("cluster-a")
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lgr.F(Warning, "message")
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ll.invalidatePrefix()
+			lgr.F(Warning, "message")
+		}
+	})
+}
+
+func TestLogLoggerPrefixCache(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	lgr.SetId("a ")
+	lgr.F(Info, "1")
+	lgr.F(Info, "2")
+	lgr.SetId("b ")
+	lgr.F(Info, "3")
+	AppendId(lgr, "c ")
+	lgr.F(Info, "4")
+	ll.Instance().SetPrefix("d ")
+	lgr.F(Info, "5")
+	ll.SetTag("t")
+	lgr.F(Info, "6")
+	ll.SetLabelStyle(LabelName)
+	lgr.F(Info, "7")
+	ll.SetLabelPadding(true)
+	lgr.F(Info, "8")
+	ll.SetIdColor(ColorAlways)
+	lgr.F(Info, "9")
+	ll.SetIdColor(ColorNever).SetPriorityPrefix(false)
+	lgr.F(Info, "10")
+
+	exp := "a [I] 1\na [I] 2\nb [I] 3\nb.c  [I] 4\nd [I] 5\n" +
+		"t d [I] 6\nt d [Info] 7\nt d [Info   ] 8\n" +
+		"t " + idColor("d ") + "d " + colorReset + "[Info   ] 9\nt d 10\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output:\n%q\n%q", s, exp)
+	}
+}
+
+func TestLogLoggerFAt(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	inst := lgr.(*LogLogger).Instance()
+	inst.SetFlags(log.LstdFlags | log.Lmicroseconds | log.LUTC)
+	lgr.SetId("id ")
+
+	when := time.Date(2021, 3, 4, 5, 6, 7, 89000, time.UTC)
+	FAt(lgr, when, Info, "at %d", 1)
+	if s := sb.String(); s != "2021/03/04 05:06:07.000089 id [I] at 1\n" {
+		t.Errorf("wrong FAt: %q", s)
+	}
+	sb.Reset()
+
+	inst.SetFlags(log.Lshortfile)
+	lgr.F(Info, "caller")
+	if s := sb.String(); !strings.HasPrefix(s, "id logwrap_test.go:") || !strings.HasSuffix(s, ": [I] caller\n") {
+		t.Errorf("wrong caller: %q", s)
+	}
+	sb.Reset()
+
+	// Loggers that don't support FAt get the time in the message.
+	FAt(MakeMaxLineLogger(lgr, 100), when, Error, "fallback")
+	if s := sb.String(); !strings.HasSuffix(s, "[E] 2021-03-04T05:06:07.000089Z fallback\n") {
+		t.Errorf("wrong fallback: %q", s)
+	}
+}
+
+func TestLogLoggerLabelStyle(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	type testCase struct {
+		pri  Priority
+		lvl  string
+		both string
+	}
+	testCases := []testCase{
+		{Emerg, "0", "0!"},
+		{Crit, "2", "2C"},
+		{Error, "3", "3E"},
+		{Warning, "4", "4W"},
+		{Notice, "5", "5N"},
+		{Info, "6", "6I"},
+		{Debug, "7", "7D"},
+	}
+	for _, tc := range testCases {
+		ll.SetLabelStyle(LabelLevel)
+		lgr.F(tc.pri, "m")
+		ll.SetLabelStyle(LabelLevelLetter)
+		lgr.F(tc.pri, "m")
+		ll.SetLabelStyle(LabelName)
+		lgr.F(tc.pri, "m")
+		exp := fmt.Sprintf("[%s] m\n[%s] m\n[%s] m\n", tc.lvl, tc.both, tc.pri)
+		if s := sb.String(); s != exp {
+			t.Errorf("%s wrong: %q", tc.pri, s)
+		}
+		sb.Reset()
+	}
+	if v := unsetPriority.Level(); v != -1 {
+		t.Errorf("wrong unset level: %d", v)
+	}
+}
+
+func TestLogLoggerLabelEmoji(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	// Configuring symbols does not select the style.
+	ll.SetLabelEmoji(map[Priority]string{Error: "E!"})
+	lgr.F(Error, "m")
+	ll.SetLabelEmoji(nil).SetLabelStyle(LabelEmoji).SetLabelPadding(true)
+	lgr.F(Error, "m")
+	lgr.F(Warning, "m")
+	emoji := map[Priority]string{
+		Error: "\u26a0\ufe0f",
+		Info:  "\u2139",
+	}
+	ll.SetLabelEmoji(emoji)
+	emoji[Error] = "changed"
+	lgr.F(Error, "m")
+	lgr.F(Info, "m")
+	lgr.F(Debug, "m")
+
+	exp := "[E] m\n[\U0001F7E5] m\n[\U0001F7E8] m\n" +
+		"[\u26a0\ufe0f] m\n[\u2139] m\n[D] m\n"
+	s := sb.String()
+	if s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	if !utf8.ValidString(s) {
+		t.Errorf("invalid UTF-8: %q", s)
+	}
+	for pri := Emerg; pri <= Debug; pri++ {
+		if e := DefaultLabelEmoji[pri]; utf8.RuneCountInString(e) != 1 {
+			t.Errorf("%s bad default: %q", pri, e)
+		}
+	}
+}
+
+func TestLogLoggerSetPriorityUntil(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb).SetPriority(Warning)
+	ll := lgr.(*LogLogger)
+
+	ll.SetPriorityUntil(Debug, time.Hour)
+	lgr.F(Debug, "kept")
+	ll.SetPriorityUntil(Info, time.Hour)
+	if p := lgr.Priority(); p != Info {
+		t.Errorf("wrong temporary priority: %s", p)
+	}
+	if s := sb.String(); s != "[D] kept\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	sb.Reset()
+
+	// An expired deadline reverts to the original priority.
+	ll.SetPriorityUntil(Debug, 0)
+	lgr.F(Debug, "dropped")
+	if p := lgr.Priority(); p != Warning {
+		t.Errorf("priority not reverted: %s", p)
+	}
+	if s := sb.String(); s != "" {
+		t.Errorf("wrong output: %q", s)
+	}
+
+	// SetPriority cancels the revert.
+	ll.SetPriorityUntil(Info, 0)
+	lgr.SetPriority(Debug)
+	if p := lgr.Priority(); p != Debug {
+		t.Errorf("revert not cancelled: %s", p)
+	}
+}
+
+func TestLogLoggerLabelPadding(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+	ll.SetLabelStyle(LabelName).SetLabelPadding(true)
+
+	col := -1
+	for pri := Emerg; pri <= Debug; pri++ {
+		lgr.F(pri, "msg")
+		s := sb.String()
+		sb.Reset()
+		if !strings.HasPrefix(s, "["+pri.String()) {
+			t.Errorf("wrong label: %q", s)
+		}
+		c := strings.Index(s, "msg")
+		if col < 0 {
+			col = c
+		} else if c != col {
+			t.Errorf("%s misaligned: %q", pri, s)
+		}
+	}
+	if col != len("[Warning] ") {
+		t.Errorf("wrong column: %d", col)
+	}
+
+	ll.SetLabelStyle(LabelLetter)
+	lgr.F(Info, "msg")
+	if s := sb.String(); s != "[I] msg\n" {
+		t.Errorf("letter padded: %q", s)
+	}
+}
+
+func TestFGuarded(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lgr.SetPriority(Warning)
+
+	calls := 0
+	msg := func() string {
+		calls++
+		return fmt.Sprintf("call %d", calls)
+	}
+	FGuarded(lgr, Info, msg)
+	FGuarded(lgr, Error, msg)
+	// Fallback through a logger that does not implement GuardedLogger.
+	FGuarded(MakeMaxLineLogger(lgr, 80), Debug, msg)
+	FGuarded(MakeMaxLineLogger(lgr, 80), Warning, msg)
+	if calls != 2 {
+		t.Errorf("wrong call count: %d", calls)
+	}
+	if s := sb.String(); s != "[E] call 1\n[W] call 2\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestFGuardedAtomic(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb).SetPriority(Info)
+	elgr := MakeEmissionLogger(lgr, 4)
+
+	// A priority change requested while msg runs takes effect only after
+	// the message has been emitted.
+	done := make(chan struct{})
+	FGuarded(elgr, Info, func() string {
+		go func() {
+			lgr.SetPriority(Warning)
+			close(done)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		return "guarded"
+	})
+	<-done
+	if p := lgr.Priority(); p != Warning {
+		t.Errorf("priority not changed: %s", p)
+	}
+	FGuarded(elgr, Info, func() string {
+		t.Errorf("msg invoked for disabled priority")
+		return "filtered"
+	})
+	if s := sb.String(); s != "[I] guarded\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	if snap := elgr.Snapshot(); len(snap) != 1 || len(snap[Info]) != 1 {
+		t.Errorf("wrong emissions: %v", snap)
+	}
+}
+
+func stackDepthRecurse(lgr Logger, n int) {
+	if n > 0 {
+		stackDepthRecurse(lgr, n-1)
+		return
+	}
+	lgr.F(Info, "deep")
+}
+
+func TestLogLoggerStackDepth(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lgr.F(Info, "off")
+	if s := sb.String(); s != "[I] off\n" {
+		t.Errorf("disabled depth rendered: %q", s)
+	}
+	lgr.(*LogLogger).SetStackDepth(true)
+
+	depth := func(n int) int {
+		sb.Reset()
+		stackDepthRecurse(lgr, n)
+		var d int
+		if _, err := fmt.Sscanf(sb.String(), "[I] (d=%d) deep", &d); err != nil {
+			t.Fatalf("bad output %q: %v", sb.String(), err)
+		}
+		return d
+	}
+	if d0, d5 := depth(0), depth(5); d5-d0 != 5 {
+		t.Errorf("wrong depth change: %d to %d", d0, d5)
+	}
+	sb.Reset()
+	stackDepthRecurse(lgr, 2*MaxStackDepth)
+	if s := sb.String(); s != "[I] (d=256+) deep\n" {
+		t.Errorf("wrong capped depth: %q", s)
+	}
+}
+
+// wholeLineWriter is an unsynchronized io.Writer that records each Write,
+// counting calls that overlap another call or that do not contain exactly
+// one complete line.
+type wholeLineWriter struct {
+	active  int32
+	overlap int32
+	partial int
+	lines   map[string]int
+}
+
+func (w *wholeLineWriter) Write(data []byte) (int, error) {
+	if atomic.AddInt32(&w.active, 1) != 1 {
+		atomic.AddInt32(&w.overlap, 1)
+	}
+	defer atomic.AddInt32(&w.active, -1)
+	if bytes.IndexByte(data, '\n') != len(data)-1 {
+		w.partial++
+	}
+	w.lines[string(data)]++
+	return len(data), nil
+}
+
+func TestLogLoggerSingleWrite(t *testing.T) {
+	w := &wholeLineWriter{
+		lines: make(map[string]int),
+	}
+	lgr := LogLogMaker(nil)
+	lgr.(*LogLogger).Instance().SetOutput(w)
+	lgr.(*LogLogger).Instance().SetFlags(log.Lmicroseconds | log.Lshortfile)
+	lgr.SetId("id ")
+	lgr.SetPriority(Debug)
+
+	const workers = 8
+	const count = 200
+	var wg sync.WaitGroup
+	for g := 0; g < workers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				switch i % 3 {
+				case 0:
+					lgr.F(Info, "worker %d message %d %s", g, i, strings.Repeat("x", 100))
+				case 1:
+					FF(lgr, Debug, Fields{"g": g, "i": i}, "fields")
+				default:
+					FAt(lgr, time.Now(), Notice, "timed %d %d", g, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	n := 0
+	for _, c := range w.lines {
+		n += c
+	}
+	if n != workers*count {
+		t.Errorf("wrong write count: %d", n)
+	}
+	if w.overlap != 0 || w.partial != 0 {
+		t.Errorf("%d overlapping and %d partial writes", w.overlap, w.partial)
+	}
+}
+
+func TestLogLoggerOutput(t *testing.T) {
+	var sb1, sb2 strings.Builder
+	ll := newTestLogger(&sb1).(*LogLogger)
+	if w := ll.Output(); w != &sb1 {
+		t.Errorf("wrong initial output: %v", w)
+	}
+	ll.SetOutput(&sb2).F(Info, "redirected")
+	if w := ll.Output(); w != &sb2 {
+		t.Errorf("wrong set output: %v", w)
+	}
+	if sb1.Len() != 0 || sb2.String() != "[I] redirected\n" {
+		t.Errorf("wrong destination: %q %q", sb1.String(), sb2.String())
+	}
+	ll.Instance().SetOutput(&sb1)
+	if w := ll.Output(); w != &sb1 {
+		t.Errorf("instance change not reflected: %v", w)
+	}
+	ll.CaptureOutput(func() {
+		if w := ll.Output(); w == &sb1 {
+			t.Errorf("capture not reflected")
+		}
+	})
+}
+
+func TestLogLoggerCaptureOutput(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	lgr.F(Info, "before")
+	s := ll.CaptureOutput(func() {
+		lgr.F(Info, "during %d", 1)
+		lgr.F(Debug, "during %d", 2)
+	})
+	lgr.F(Info, "after")
+	if s != "[I] during 1\n[D] during 2\n" {
+		t.Errorf("wrong capture: %q", s)
+	}
+	if s := sb.String(); s != "[I] before\n[I] after\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+
+	sb.Reset()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic not propagated")
+			}
+		}()
+		ll.CaptureOutput(func() {
+			lgr.F(Info, "lost")
+			panic("oops")
+		})
+	}()
+	lgr.F(Info, "restored")
+	if s := sb.String(); s != "[I] restored\n" {
+		t.Errorf("output not restored: %q", s)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := ll.CaptureOutput(func() {
+				lgr.F(Info, "g%d", i)
+			})
+			if exp := fmt.Sprintf("[I] g%d\n", i); s != exp {
+				t.Errorf("wrong concurrent capture: %q", s)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLogLoggerPriorityFlags(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+	lgr.SetId("id: ")
+
+	ll.SetPriorityFlags(Debug, log.Lshortfile)
+	lgr.F(Info, "clean")
+	lgr.F(Debug, "detailed")
+	key := filepath.Base(hereKey(-1))
+	ll.SetPriorityFlags(Debug, -1)
+	lgr.F(Debug, "restored")
+
+	exp := "id: [I] clean\n" + key + ": id: [D] detailed\nid: [D] restored\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output:\n got %q\nwant %q", s, exp)
+	}
+}
+
+func TestLogLoggerFProgress(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	// Not a terminal: emitted as normal lines.
+	ll.FProgress(Info, "%d%%", 10)
+	ll.FProgress(Info, "%d%%", 20)
+	if s := sb.String(); s != "[I] 10%\n[I] 20%\n" {
+		t.Errorf("wrong fallback output: %q", s)
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	ll.SetOutput(f)
+	// Pretend the file is a terminal.
+	ll.tty = ttyCache{
+		w:     f,
+		isTTY: true,
+	}
+	ll.FProgress(Info, "%d%%", 10)
+	ll.FProgress(Info, "%d%%", 100)
+	lgr.F(Notice, "done")
+	lgr.F(Notice, "after")
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "\r[I] 10%\x1b[K\r[I] 100%\x1b[K\n[N] done\n[N] after\n"
+	if s := string(data); s != exp {
+		t.Errorf("wrong terminal output: %q", s)
+	}
+}
+
+func TestLogLoggerAudit(t *testing.T) {
+	var sb, asb strings.Builder
+	lgr := newTestLogger(&sb).SetPriority(Emerg)
+	ll := lgr.(*LogLogger)
+	lgr.SetId("id ")
+
+	ll.SetPriorityPrefix(false).SetLabelStyle(LabelName)
+	Audit(lgr, "user %s logged in", "bob")
+	lgr.F(Notice, "filtered")
+	if s := sb.String(); s != "id [AUDIT] user bob logged in\n" {
+		t.Errorf("wrong audit: %q", s)
+	}
+	sb.Reset()
+
+	alog := newTestLogger(&asb).SetPriority(Emerg)
+	ll.SetAuditSink(alog)
+	Audit(lgr, "config changed")
+	if s := sb.String(); s != "" {
+		t.Errorf("audit not routed: %q", s)
+	}
+	if s := asb.String(); s != "[AUDIT] config changed\n" {
+		t.Errorf("wrong routed audit: %q", s)
+	}
+	asb.Reset()
+
+	// Loggers that don't implement Auditor get a tagged message at Emerg.
+	alog.SetPriority(Warning)
+	Audit(struct{ ImmutableLogger }{alog}, "fallback")
+	if s := asb.String(); s != "[!] [AUDIT] fallback\n" {
+		t.Errorf("wrong fallback audit: %q", s)
+	}
+}
+
+func TestAuditDecorators(t *testing.T) {
+	var sb strings.Builder
+	base := newTestLogger(&sb).SetPriority(Emerg)
+
+	quiet := MakeQuietLogger(base)
+	quiet.QuietUntil(time.Now().Add(time.Hour), Emerg)
+	decorators := map[string]ImmutableLogger{
+		"adaptive":   MakeAdaptiveLogger(base, 1),
+		"budget":     MakeBudgetLogger(base, 1),
+		"bound":      BindPriority(base, Debug),
+		"callsite":   MakeCallSiteLogger(base),
+		"content":    MakeContentPriorityLogger(base, []PriorityRule{{regexp.MustCompile("."), Debug}}),
+		"emission":   MakeEmissionLogger(base, 1),
+		"failover":   MakeFailoverLogger(base, base),
+		"globalrate": MakeGlobalRateLimitLogger(base, 1, 1),
+		"handle":     MakeLoggerHandle(base),
+		"keyedonce":  MakeKeyedOnceLogger(base, time.Hour, 1),
+		"maxline":    MakeMaxLineLogger(base, 4),
+		"once":       MakeOnceLogger(base, 1),
+		"op":         MakeOpLogger(base),
+		"quiet":      quiet,
+		"ratelimit":  MakeRateLimitLogger(base, map[Priority]RateLimit{Emerg: {1, time.Hour}}),
+		"report":     MakeReportLogger(base),
+		"severity":   MakeSeverityLogger(base),
+	}
+	for name, lgr := range decorators {
+		sb.Reset()
+		Audit(lgr, "audited")
+		Audit(lgr, "audited")
+		if s := sb.String(); s != "[AUDIT] audited\n[AUDIT] audited\n" {
+			t.Errorf("%s: wrong audit: %q", name, s)
+		}
+	}
+
+	sb.Reset()
+	Audit(MakeRunIdLogger(base), "run")
+	if s := sb.String(); !regexp.MustCompile(`^\[AUDIT\] \(run=[^)]+\) run\n$`).MatchString(s) {
+		t.Errorf("wrong run id audit: %q", s)
+	}
+
+	// Channel loggers forward the audit through the channel.
+	sb.Reset()
+	clgr, ch := MakeChanLogger(base, 2)
+	Audit(PrefixedChanLogger(clgr, "p: "), "queued")
+	e := (<-ch).(MessageEmitter)
+	if e.Priority() != Emerg {
+		t.Errorf("wrong channel priority: %s", e.Priority())
+	}
+	e.Emit()
+	if s := sb.String(); s != "[AUDIT] p: queued\n" {
+		t.Errorf("wrong channel audit: %q", s)
+	}
+}
+
+func TestLogLoggerSetTag(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	ll.SetTag("cluster-a")
+	lgr.F(Info, "no id")
+	lgr.SetId("svc")
+	AppendId(lgr, "db ")
+	lgr.F(Info, "with id")
+	ll.Instance().SetFlags(log.Ldate | log.Lmsgprefix)
+	lgr.F(Info, "dated")
+	ll.Instance().SetFlags(log.Lmsgprefix)
+	ll.SetTag("")
+	lgr.F(Info, "untagged")
+
+	lines := strings.Split(sb.String(), "\n")
+	exp := []string{
+		"cluster-a [I] no id",
+		"cluster-a svc.db [I] with id",
+		"",
+		"svc.db [I] untagged",
+	}
+	for i, e := range exp {
+		if e != "" && lines[i] != e {
+			t.Errorf("line %d wrong: %q", i, lines[i])
+		}
+	}
+	if !regexp.MustCompile(`^\d{4}/\d\d/\d\d cluster-a svc.db \[I\] dated$`).MatchString(lines[2]) {
+		t.Errorf("wrong dated line: %q", lines[2])
+	}
+}
+
+func TestAppendId(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	AppendId(lgr, "svc ")
+	AppendId(lgr, "sub")
+	AppendId(lgr, "worker")
+	lgr.F(Info, "msg")
+	if s := sb.String(); s != "svc.sub.worker [I] msg\n" {
+		t.Errorf("wrong appended id: %q", s)
+	}
+	sb.Reset()
+
+	lgr.(*LogLogger).SetIdSeparator("/")
+	AppendId(lgr, "x").F(Info, "msg")
+	if s := sb.String(); s != "svc.sub.worker/x [I] msg\n" {
+		t.Errorf("wrong separator: %q", s)
+	}
+	sb.Reset()
+
+	lgr.SetId("new:")
+	lgr.F(Info, "msg")
+	if s := sb.String(); s != "new:[I] msg\n" {
+		t.Errorf("SetId did not replace: %q", s)
+	}
+	sb.Reset()
+
+	if AppendId(NullLogMaker(nil), "x") == nil {
+		t.Errorf("null AppendId failed")
+	}
+	AppendId(MakeOnceLogger(lgr, 1), "fallback ").F(Info, "msg")
+	if s := sb.String(); s != "fallback [I] msg\n" {
+		t.Errorf("wrong fallback: %q", s)
+	}
+}
+
+func TestLogLoggerPriorityNotices(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb).SetPriority(Warning)
+	ll := lgr.(*LogLogger)
+
+	lgr.SetPriority(Info)
+	if s := sb.String(); s != "" {
+		t.Errorf("notice while disabled: %q", s)
+	}
+
+	ll.SetPriorityNotices(true)
+	lgr.SetPriority(Info)
+	lgr.SetPriority(Error)
+	ll.SetPriorityUntil(Debug, 0)
+	_ = lgr.Priority()
+	exp := "[N] log priority changed from Info to Error\n" +
+		"[N] log priority changed from Error to Debug\n" +
+		"[N] log priority changed from Debug to Error\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong notices: %q", s)
+	}
+}
+
+type failWriter struct{}
+
+var errWriteFailed = errors.New("write failed")
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errWriteFailed
+}
+
+func TestLogLoggerErrorSink(t *testing.T) {
+	var sb strings.Builder
+	sink := newTestLogger(&sb)
+
+	lgr := LogLogMaker(nil)
+	ll := lgr.(*LogLogger)
+	ll.Instance().SetOutput(failWriter{})
+
+	lgr.F(Error, "lost")
+	if s := sb.String(); s != "" {
+		t.Errorf("unexpected report: %q", s)
+	}
+
+	ll.SetErrorSink(sink)
+	lgr.F(Error, "lost")
+	if s := sb.String(); s != "[E] logwrap: write failed: write failed\n" {
+		t.Errorf("wrong report: %q", s)
+	}
+	sb.Reset()
+
+	// Self-reporting is ignored rather than recursing.
+	ll.SetErrorSink(lgr)
+	lgr.F(Error, "lost")
+	ll.SetErrorSink(nil)
+	lgr.F(Error, "lost")
+	if s := sb.String(); s != "" {
+		t.Errorf("unexpected report: %q", s)
+	}
+}
+
 func TestNullLogger(t *testing.T) {
 	lgr := NullLogMaker(nil)
 	lgr.F(Emerg, "made it this far")
@@ -196,6 +1009,39 @@ func TestMakePriWrapper(t *testing.T) {
 	}
 }
 
+func TestMakePriLogf(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	plf := MakePriLogf(lgr, Notice)
+	if p := plf.Priority(); p != Notice {
+		t.Errorf("wrong bound priority: %s", p)
+	}
+
+	var lf Logf = plf.Logf
+	lf("Test %d", 1)
+	if s := sb.String(); s != "[N] Test 1\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
+func TestBindPriority(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lgr.SetPriority(Notice)
+
+	blgr := BindPriority(lgr, Warning)
+	if p := blgr.Priority(); p != Notice {
+		t.Errorf("wrong priority: %s", p)
+	}
+	blgr.F(Debug, "debug %d", 1)
+	blgr.F(Emerg, "emerg %d", 2)
+	BindPriority(lgr, Info).F(Crit, "filtered")
+	if s := sb.String(); s != "[W] debug 1\n[W] emerg 2\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+}
+
 func TestMakePriPr(t *testing.T) {
 	var sb strings.Builder
 	lgr := LogLogMaker(nil)
@@ -233,6 +1079,37 @@ func TestMakePriPr(t *testing.T) {
 	ck(t, Warning)
 }
 
+func TestPriPrAt(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	lpr := MakePriPr(lgr)
+
+	lpr.At(Warning)("escalated %d", 1)
+	lpr.At(Info)("normal %d", 2)
+	lgr.SetPriority(Notice)
+	lpr.At(Info)("filtered")
+	if s := sb.String(); s != "[W] escalated 1\n[I] normal 2\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	sb.Reset()
+
+	// Without a logger the field for the priority is used.
+	var cs countingStringer
+	lpr = PriPr{
+		E: lpr.E,
+	}
+	lpr.At(Error)("error %s", &cs)
+	lpr.At(Notice)("dropped %s", &cs)
+	lpr.At(unsetPriority)("dropped %s", &cs)
+	lpr.If(false).At(Error)("dropped %s", &cs)
+	if s := sb.String(); s != "[E] error str\n" {
+		t.Errorf("wrong fallback output: %q", s)
+	}
+	if cs != 1 {
+		t.Errorf("wrong format count: %d", cs)
+	}
+}
+
 type logOwner struct {
 	lgr Logger
 }
@@ -245,6 +1122,67 @@ func (lo *logOwner) LogSetPriority(pri Priority) {
 	lo.lgr.SetPriority(pri)
 }
 
+// countingStringer counts the number of times it is formatted.
+type countingStringer int
+
+func (v *countingStringer) String() string {
+	*v++
+	return "str"
+}
+
+// reentrantStringer logs to a logger when it is formatted.
+type reentrantStringer struct {
+	lgr Logger
+}
+
+func (v reentrantStringer) String() string {
+	v.lgr.F(Debug, "inner")
+	return "outer"
+}
+
+func TestLogLoggerReentrant(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	arg := reentrantStringer{lgr}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lgr.F(Info, "%s", arg)
+		FF(lgr, Info, Fields{"k": arg}, "%s", arg)
+		FAt(lgr, time.Now(), Info, "%s", arg)
+		_ = FChecked(lgr, Info, "%s", arg)
+		lgr.(*LogLogger).FProgress(Info, "%s", arg)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock formatting arguments")
+	}
+	if n := strings.Count(sb.String(), "[D] inner\n"); n != 6 {
+		t.Errorf("wrong inner count %d: %q", n, sb.String())
+	}
+}
+
+func TestFIf(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	var cs countingStringer
+
+	FIf(lgr, false, Info, "skipped %s", &cs)
+	FIf(lgr, true, Info, "emitted %s", &cs)
+	lpr := MakePriPr(lgr)
+	lpr.If(false).W("skipped %s", &cs)
+	lpr.If(false).Em("skipped %s", &cs)
+	lpr.If(true).W("emitted %s", &cs)
+	if s := sb.String(); s != "[I] emitted str\n[W] emitted str\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	if cs != 2 {
+		t.Errorf("wrong format count: %d", cs)
+	}
+}
+
 func TestLogOwner(t *testing.T) {
 	lo := &logOwner{
 		lgr: LogLogMaker(nil),
@@ -312,4 +1250,371 @@ func TestChanLogger(t *testing.T) {
 	}
 	sb.Reset()
 
+	FRequest(pcl, "r42", Info, fmt, "arg", 3)
+	m = <-lch
+	if e, ok := m.(*emittable); !ok || e.rid != "r42" || e.fmt != fmt {
+		t.Error("wrong request emittable content")
+	}
+	m.Emit()
+	if s := sb.String(); s != "" {
+		t.Errorf("filtered request emitted: %s", s)
+	}
+	blgr.SetPriority(Info)
+	m.Emit()
+	if s := sb.String(); !strings.HasSuffix(s, " [I] pfx: [r42] format: arg 3\n") {
+		t.Errorf("wrong request content: %s", s)
+	}
+	sb.Reset()
+
+	// Without a request identifier the prefix is retained.
+	FRequest(pcl, "", Info, fmt, "arg", 4)
+	m = <-lch
+	if me := m.(MessageEmitter); me.Message() != "pfx: format: arg 4" {
+		t.Errorf("wrong empty request message: %q", me.Message())
+	}
+	m.Emit()
+	if s := sb.String(); !strings.HasSuffix(s, " [I] pfx: format: arg 4\n") {
+		t.Errorf("wrong empty request content: %s", s)
+	}
+	sb.Reset()
+
+	// Loggers that don't support FRequest get the tag before F.
+	FRequest(blgr, "r43", Info, "direct")
+	if s := sb.String(); !strings.HasSuffix(s, " [I] [r43] direct\n") {
+		t.Errorf("wrong direct request content: %s", s)
+	}
+	sb.Reset()
+
+	if v := PendingFraction(blgr); v != 0 {
+		t.Errorf("non-channel fraction: %g", v)
+	}
+	if v := PendingFraction(pcl); v != 0 {
+		t.Errorf("empty fraction: %g", v)
+	}
+	lgr4, _ := MakeChanLogger(blgr, 4)
+	lgr4.F(Error, "one")
+	if v := PendingFraction(PrefixedChanLogger(lgr4, "p")); v != 0.25 {
+		t.Errorf("wrong fraction: %g", v)
+	}
+	if v := PendingFraction(PrefixedChanLogger(blgr, "p")); v != 0 {
+		t.Errorf("nil fraction: %g", v)
+	}
+
+	var lat []time.Duration
+	SetLatencyObserver(blgr, func(time.Duration) {
+		t.Error("observer set on non-channel logger")
+	})
+	SetLatencyObserver(pcl, func(d time.Duration) {
+		lat = append(lat, d)
+	})
+	lgr.F(Error, "observed")
+	m = <-lch
+	time.Sleep(time.Millisecond)
+	m.Emit()
+	SetLatencyObserver(lgr, nil)
+	lgr.F(Error, "not observed")
+	(<-lch).Emit()
+	if len(lat) != 1 || lat[0] < time.Millisecond {
+		t.Errorf("wrong latencies: %v", lat)
+	}
+}
+
+func TestMakeChanLoggerDefault(t *testing.T) {
+	defer SetDefaultChanCap(InitialChanCap)
+
+	var sb strings.Builder
+	_, ch := MakeChanLoggerDefault(newTestLogger(&sb))
+	if n := cap(ch); n != InitialChanCap {
+		t.Errorf("wrong initial cap: %d", n)
+	}
+	SetDefaultChanCap(5)
+	if _, ch = MakeChanLoggerDefault(newTestLogger(&sb)); cap(ch) != 5 {
+		t.Errorf("wrong configured cap: %d", cap(ch))
+	}
+	SetDefaultChanCap(0)
+	if _, ch = MakeChanLoggerDefault(newTestLogger(&sb)); cap(ch) != 1 {
+		t.Errorf("wrong minimum cap: %d", cap(ch))
+	}
+}
+
+func TestFDone(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+
+	select {
+	case <-FDone(blgr, Info, "direct %d", 1):
+	default:
+		t.Errorf("direct not closed")
+	}
+	if s := sb.String(); s != "[I] direct 1\n" {
+		t.Errorf("wrong direct output: %q", s)
+	}
+	sb.Reset()
+
+	lgr, lch := MakeChanLogger(blgr, 4)
+	go func() {
+		for e := range lch {
+			e.Emit()
+		}
+	}()
+	lgr.F(Info, "first")
+	done := FDone(PrefixedChanLogger(lgr, "p: "), Info, "second")
+	<-done
+	if s := sb.String(); s != "[I] first\n[I] p: second\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	<-FDone(ContextChanLogger(lgr, ctx), Info, "dropped")
+
+	// The prefix is available for grouping, as for F.
+	qlgr, qch := MakeChanLogger(blgr, 1)
+	FDone(PrefixedChanLogger(qlgr, "p: "), Info, "queued")
+	if p := EmitterPrefix(<-qch); p != "p: " {
+		t.Errorf("wrong prefix: %q", p)
+	}
+}
+
+// cvLogger records the context values passed to FContextValue.
+type cvLogger struct {
+	Logger
+	cvs []interface{}
+}
+
+func (v *cvLogger) FContextValue(cv interface{}, pri Priority, format string, args ...interface{}) {
+	v.cvs = append(v.cvs, cv)
+	v.F(pri, format, args...)
+}
+
+func TestFContextValue(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+	sink := &cvLogger{Logger: blgr}
+
+	// Plain loggers discard the value.
+	FContextValue(blgr, "span", Info, "plain %d", 1)
+	FContextValue(sink, "span", Info, "direct %d", 2)
+
+	lgr, lch := MakeChanLogger(sink, 4)
+	span := &struct{ id int }{7}
+	FContextValue(PrefixedChanLogger(lgr, "p: "), span, Info, "queued %d", 3)
+	lgr.F(Info, "no value")
+	e := <-lch
+	if cv := EmitterContextValue(e); cv != span {
+		t.Errorf("wrong emitter value: %v", cv)
+	}
+	e.Emit()
+	e = <-lch
+	if cv := EmitterContextValue(e); cv != nil {
+		t.Errorf("unexpected emitter value: %v", cv)
+	}
+	e.Emit()
+
+	exp := "[I] plain 1\n[I] direct 2\n[I] p: queued 3\n[I] no value\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+	if len(sink.cvs) != 2 || sink.cvs[0] != "span" || sink.cvs[1] != span {
+		t.Errorf("wrong values: %v", sink.cvs)
+	}
+}
+
+func TestChanStateAlignment(t *testing.T) {
+	// 64-bit atomic operations panic on 32-bit platforms unless the
+	// operand is 64-bit aligned, which is only guaranteed for the first
+	// field of an allocated struct.
+	if off := unsafe.Offsetof(chanState{}.dropped); off != 0 {
+		t.Errorf("dropped at offset %d", off)
+	}
+}
+
+func TestContextChanLogger(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+	lgr, lch := MakeChanLogger(blgr, 1)
+
+	if ContextChanLogger(blgr, context.Background()).(*chanLogger) != nil {
+		t.Errorf("incompatible logger not detected")
+	}
+	if DroppedMessages(blgr) != 0 {
+		t.Errorf("non-channel drops")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	clgr := ContextChanLogger(PrefixedChanLogger(lgr, "p: "), ctx)
+	clgr.F(Info, "sent")
+
+	// Channel is full; F blocks until cancelled.
+	done := make(chan struct{})
+	go func() {
+		clgr.F(Info, "blocked")
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+
+	// Already cancelled; F returns immediately.
+	clgr.F(Info, "abandoned")
+	if n := DroppedMessages(lgr); n != 2 {
+		t.Errorf("wrong drop count: %d", n)
+	}
+
+	(<-lch).Emit()
+	if s := sb.String(); s != "[I] p: sent\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	select {
+	case <-lch:
+		t.Errorf("dropped message sent")
+	default:
+	}
+}
+
+func TestNonBlockingChanLogger(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+	lgr, lch := MakeChanLogger(blgr, 1)
+
+	if NonBlockingChanLogger(blgr).(*chanLogger) != nil {
+		t.Errorf("incompatible logger not detected")
+	}
+	SetDropHandler(blgr, func(Priority, string) {
+		t.Errorf("handler on non-channel logger")
+	})
+
+	var drops []string
+	SetDropHandler(lgr, func(pri Priority, format string) {
+		drops = append(drops, fmt.Sprintf("%s %s", pri, format))
+	})
+	nlgr := NonBlockingChanLogger(PrefixedChanLogger(lgr, "p: "))
+	nlgr.F(Info, "sent")
+	nlgr.F(Warning, "full %d", 1)
+	<-FDone(NonBlockingChanLogger(lgr), Error, "full %d", 2)
+	if n := DroppedMessages(lgr); n != 2 {
+		t.Errorf("wrong drop count: %d", n)
+	}
+	if s := strings.Join(drops, ","); s != "Warning p: full %d,Error full %d" {
+		t.Errorf("wrong drops: %q", s)
+	}
+
+	(<-lch).Emit()
+	SetDropHandler(lgr, nil)
+	nlgr.F(Info, "sent")
+	nlgr.F(Info, "full")
+	(<-lch).Emit()
+	if s := sb.String(); s != "[I] p: sent\n[I] p: sent\n" {
+		t.Errorf("wrong output: %q", s)
+	}
+	if n := len(drops); n != 2 {
+		t.Errorf("removed handler invoked: %d", n)
+	}
+
+	// Drops from a cancelled context are also handled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	SetDropHandler(lgr, func(pri Priority, format string) {
+		drops = append(drops, format)
+	})
+	ContextChanLogger(lgr, ctx).F(Info, "abandoned")
+	if n := len(drops); n != 3 || drops[2] != "abandoned" {
+		t.Errorf("context drop not handled: %q", drops)
+	}
+}
+
+func TestSyncChanLogger(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+	lgr, lch := MakeChanLogger(blgr, 4)
+
+	if SyncChanLogger(blgr, Crit, SyncOnly).(*chanLogger) != nil {
+		t.Errorf("incompatible logger not detected")
+	}
+
+	slgr := SyncChanLogger(PrefixedChanLogger(lgr, "p: "), Crit, SyncOnly)
+	slgr.F(Info, "queued")
+	slgr.F(Emerg, "emerg")
+	<-FDone(slgr, Crit, "crit")
+	if s := sb.String(); s != "[!] p: emerg\n[C] p: crit\n" {
+		t.Errorf("wrong sync output: %q", s)
+	}
+	if n := len(lch); n != 1 {
+		t.Fatalf("wrong pending: %d", n)
+	}
+	(<-lch).Emit()
+	if s := sb.String(); !strings.HasSuffix(s, "\n[I] p: queued\n") {
+		t.Errorf("wrong queued output: %q", s)
+	}
+	sb.Reset()
+
+	qlgr := SyncChanLogger(lgr, Error, SyncAndQueue)
+	qlgr.F(Error, "both")
+	if s := sb.String(); s != "[E] both\n" {
+		t.Errorf("wrong sync output: %q", s)
+	}
+	e := (<-lch).(MessageEmitter)
+	if e.Priority() != Error || e.Message() != "both" {
+		t.Errorf("wrong queued message: %s %q", e.Priority(), e.Message())
+	}
+	sb.Reset()
+
+	// Synchronous and queued emission are serialized.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			(<-lch).Emit()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		slgr.F(Info, "queued")
+		slgr.F(Crit, "sync")
+	}
+	wg.Wait()
+	if s := sb.String(); strings.Count(s, "sync\n") != 100 || strings.Count(s, "queued\n") != 100 {
+		t.Errorf("wrong concurrent output: %q", s)
+	}
+}
+
+func TestBroadcastChanLogger(t *testing.T) {
+	var sb1, sb2 strings.Builder
+	blgr1 := newTestLogger(&sb1).SetPriority(Info)
+	blgr2 := newTestLogger(&sb2).SetPriority(Warning)
+	lgr1, lch1 := MakeChanLogger(blgr1, 4)
+	lgr2, lch2 := MakeChanLogger(blgr2, 1)
+
+	if BroadcastChanLogger(blgr1, nil).(*broadcastLogger) != nil {
+		t.Errorf("incompatible loggers not detected")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	lgr := BroadcastChanLogger(PrefixedChanLogger(lgr1, "p: "), blgr1,
+		ContextChanLogger(lgr2, ctx))
+	if p := lgr.Priority(); p != Info {
+		t.Errorf("wrong priority: %s", p)
+	}
+
+	lgr.F(Info, "info")
+	lgr.F(Warning, "warning")
+	if n := len(lch1); n != 2 {
+		t.Errorf("wrong first pending: %d", n)
+	}
+	if n := DroppedMessages(lgr2); n != 2 {
+		t.Errorf("wrong second drops: %d", n)
+	}
+
+	lgr = BroadcastChanLogger(lgr1, lgr2)
+	lgr.F(Info, "both")
+	for len(lch1) > 0 {
+		(<-lch1).Emit()
+	}
+	(<-lch2).Emit()
+	if s := sb1.String(); s != "[I] p: info\n[W] p: warning\n[I] both\n" {
+		t.Errorf("wrong first output: %q", s)
+	}
+	if s := sb2.String(); s != "" {
+		t.Errorf("wrong second output: %q", s)
+	}
 }
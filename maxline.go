@@ -0,0 +1,140 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// LinePolicy selects how MaxLineLogger handles messages that exceed its
+// limit.
+type LinePolicy int
+
+const (
+	// TruncateLine cuts the message to fit the limit, replacing the tail
+	// with LineEllipsis.
+	TruncateLine LinePolicy = iota
+	// SplitLine emits the message as a sequence of messages each of
+	// which fits the limit.  Each continuation is a complete log message,
+	// so it carries the priority and identifier prefixes added by the
+	// underlying logger.
+	SplitLine
+)
+
+// LineEllipsis is the marker that replaces the tail of a truncated message.
+const LineEllipsis = "..."
+
+// MaxLineLogger wraps a Logger to ensure that no formatted message exceeds a
+// fixed number of bytes.  This protects messages destined for sinks that
+// silently drop lines longer than some limit.
+//
+// The limit applies to the formatted message only, not to any prefixes or
+// timestamps added by the underlying logger.  Messages are never cut within a
+// UTF-8 encoded rune.  A rune that is itself longer than the limit cannot be
+// emitted, and is replaced by as much of LineEllipsis as fits.
+type MaxLineLogger struct {
+	lgr    Logger
+	max    int
+	policy LinePolicy
+}
+
+// MakeMaxLineLogger returns a MaxLineLogger that forwards to lgr messages
+// limited to maxBytes bytes.  The initial policy is TruncateLine.  Values of
+// maxBytes less than 1 are replaced by 1.
+func MakeMaxLineLogger(lgr Logger, maxBytes int) *MaxLineLogger {
+	if maxBytes < 1 {
+		maxBytes = 1
+	}
+	return &MaxLineLogger{
+		lgr: lgr,
+		max: maxBytes,
+	}
+}
+
+// SetPolicy selects how over-long messages are handled.
+func (v *MaxLineLogger) SetPolicy(policy LinePolicy) *MaxLineLogger {
+	v.policy = policy
+	return v
+}
+
+// Priority per ImmutableLogger.
+func (v *MaxLineLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  Messages are formatted only if the underlying logger
+// would emit them.
+func (v *MaxLineLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	s := fmt.Sprintf(format, args...)
+	if len(s) <= v.max {
+		v.lgr.F(pri, "%s", s)
+		return
+	}
+	if v.policy == SplitLine {
+		for len(s) > 0 {
+			n := runeCut(s, v.max)
+			if n == 0 {
+				_, n = utf8.DecodeRuneInString(s)
+				v.lgr.F(pri, "%s", v.ellipsis())
+			} else {
+				v.lgr.F(pri, "%s", s[:n])
+			}
+			s = s[n:]
+		}
+		return
+	}
+	if v.max <= len(LineEllipsis) {
+		if n := runeCut(s, v.max); n > 0 {
+			v.lgr.F(pri, "%s", s[:n])
+		} else {
+			v.lgr.F(pri, "%s", v.ellipsis())
+		}
+		return
+	}
+	v.lgr.F(pri, "%s%s", s[:runeCut(s, v.max-len(LineEllipsis))], LineEllipsis)
+}
+
+// Audit per Auditor.  Audit messages are passed through complete, as a
+// compliance record must not be altered.
+func (v *MaxLineLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// ellipsis returns as much of LineEllipsis as fits the limit.
+func (v *MaxLineLogger) ellipsis() string {
+	if v.max < len(LineEllipsis) {
+		return LineEllipsis[:v.max]
+	}
+	return LineEllipsis
+}
+
+// SetId per Logger.
+func (v *MaxLineLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *MaxLineLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
+
+// runeCut returns the length of the longest prefix of s that is no longer
+// than n bytes and does not end within a multi-byte rune.  This is zero if
+// the first rune of s is longer than n bytes.
+func runeCut(s string, n int) int {
+	if n >= len(s) {
+		return len(s)
+	}
+	c := n
+	for c > 0 && !utf8.RuneStart(s[c]) {
+		c--
+	}
+	return c
+}
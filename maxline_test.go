@@ -0,0 +1,66 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxLineLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeMaxLineLogger(newTestLogger(&sb), 8)
+
+	lgr.F(Info, "%s", "short")
+	if s := sb.String(); s != "[I] short\n" {
+		t.Errorf("short wrong: %q", s)
+	}
+	sb.Reset()
+
+	lgr.F(Info, "%s", "0123456789abc")
+	if s := sb.String(); s != "[I] 01234...\n" {
+		t.Errorf("truncate wrong: %q", s)
+	}
+	sb.Reset()
+
+	// Do not cut within the two-byte runes.
+	lgr.F(Info, "%s", "0123éééé")
+	if s := sb.String(); s != "[I] 0123...\n" {
+		t.Errorf("truncate rune wrong: %q", s)
+	}
+	sb.Reset()
+
+	lgr.F(Debug, "drop %s", "me")
+	lgr.SetPriority(Warning)
+	lgr.F(Debug, "drop %s", "me")
+	if s := sb.String(); s != "[D] drop me\n" {
+		t.Errorf("filter wrong: %q", s)
+	}
+	sb.Reset()
+
+	lgr.SetId("id ").(*MaxLineLogger).SetPolicy(SplitLine)
+	lgr.F(Error, "%s", "0123456789abcéé")
+	if s := sb.String(); s != "id [E] 01234567\nid [E] 89abcé\nid [E] é\n" {
+		t.Errorf("split wrong: %q", s)
+	}
+	sb.Reset()
+
+	lgr = MakeMaxLineLogger(lgr, 0)
+	if lgr.max != 1 {
+		t.Errorf("max not clamped: %d", lgr.max)
+	}
+	lgr.F(Error, "%s", "éa")
+	if s := sb.String(); s != "id [E] .\n" {
+		t.Errorf("tiny truncate wrong: %q", s)
+	}
+	sb.Reset()
+
+	// Runes longer than the limit are replaced in split lines too.
+	lgr = MakeMaxLineLogger(newTestLogger(&sb), 2)
+	lgr.SetPolicy(SplitLine)
+	lgr.F(Error, "%s", "a€bc")
+	if s := sb.String(); s != "[E] a\n[E] ..\n[E] bc\n" {
+		t.Errorf("tiny split wrong: %q", s)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrInvalidMmapFile indicates that a file does not hold a ring written by
+// MmapWriter.
+var ErrInvalidMmapFile = errors.New("invalid mmap log file")
+
+// mmapMagic identifies a file holding a MmapWriter ring.
+const mmapMagic = "LWMMAP1\n"
+
+// MmapHeaderSize is the number of bytes at the start of a file used by
+// MmapWriter to record the state of the ring.  The remainder of the file
+// holds message text.
+const MmapHeaderSize = 24
+
+// Offsets of the header fields that follow mmapMagic.  Both are stored as
+// little-endian 64-bit unsigned integers.
+const (
+	mmapOffsetPos  = 8
+	mmapWrappedPos = 16
+)
+
+// MmapWriter is an io.Writer that stores what is written to it in a ring
+// within a fixed-size file mapped into memory.  Writes are copies into the
+// mapping, so high rates of messages can be captured without a system call
+// per message, and on platforms that support memory mapping the contents
+// survive a crash of the process.
+//
+// Once the ring is full each write overwrites the oldest text, so the file
+// always holds the most recent output.  Overwriting is by byte, not by
+// message, so the oldest retained line is usually cut at its start.  Since
+// the ring does not record where lines begin, Contents and ReadMmapFile
+// discard the text up to and including the first newline once the ring has
+// wrapped.  A single write larger than the ring retains only its end.
+//
+// On platforms without memory mapping the ring is held in ordinary memory
+// and written to the file only by Sync and Close.  All methods are safe for
+// concurrent use.
+type MmapWriter struct {
+	mu   sync.Mutex
+	f    *os.File
+	m    []byte
+	data []byte
+	off  int
+}
+
+// OpenMmapWriter opens or creates the file at path with a total size of
+// size bytes and maps it into memory.  If the file already holds a ring of
+// the same size written by MmapWriter, new writes continue after the
+// existing content; otherwise the file is reinitialized and its previous
+// content lost.  Use ReadMmapFile first to preserve the output of a
+// previous run.  size must exceed MmapHeaderSize.
+func OpenMmapWriter(path string, size int64) (*MmapWriter, error) {
+	if size <= MmapHeaderSize || int64(int(size)) != size {
+		return nil, os.ErrInvalid
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	resume := false
+	var hdr [MmapHeaderSize]byte
+	if fi, err := f.Stat(); err == nil && fi.Size() == size {
+		if _, err := f.ReadAt(hdr[:], 0); err == nil {
+			_, _, err = mmapHeader(hdr[:], int(size-MmapHeaderSize))
+			resume = err == nil
+		}
+	}
+	if !resume {
+		err = f.Truncate(0)
+		if err == nil {
+			err = f.Truncate(size)
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	m, err := mmapFile(f, int(size))
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	w := &MmapWriter{
+		f:    f,
+		m:    m,
+		data: m[MmapHeaderSize:],
+	}
+	if resume {
+		w.off, _, _ = mmapHeader(m, len(w.data))
+	} else {
+		copy(m, mmapMagic)
+	}
+	return w, nil
+}
+
+// mmapHeader validates the header in buf, which describes a ring of n
+// bytes, and returns the offset of the next write and whether the ring has
+// wrapped.
+func mmapHeader(buf []byte, n int) (off int, wrapped bool, err error) {
+	if len(buf) < MmapHeaderSize || string(buf[:len(mmapMagic)]) != mmapMagic {
+		return 0, false, ErrInvalidMmapFile
+	}
+	o := binary.LittleEndian.Uint64(buf[mmapOffsetPos:])
+	if o >= uint64(n) {
+		return 0, false, ErrInvalidMmapFile
+	}
+	return int(o), binary.LittleEndian.Uint64(buf[mmapWrappedPos:]) != 0, nil
+}
+
+// Write copies data into the ring, overwriting the oldest text if
+// necessary.  It never fails, unless the writer is closed.
+func (w *MmapWriter) Write(data []byte) (int, error) {
+	n := len(data)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.m == nil {
+		return 0, os.ErrClosed
+	}
+	wrapped := false
+	if len(data) >= len(w.data) {
+		copy(w.data, data[len(data)-len(w.data):])
+		w.off = 0
+		wrapped = true
+	} else {
+		c := copy(w.data[w.off:], data)
+		w.off += c
+		if c < len(data) {
+			w.off = copy(w.data, data[c:])
+			wrapped = true
+		} else if w.off == len(w.data) {
+			w.off = 0
+			wrapped = true
+		}
+	}
+	binary.LittleEndian.PutUint64(w.m[mmapOffsetPos:], uint64(w.off))
+	if wrapped {
+		binary.LittleEndian.PutUint64(w.m[mmapWrappedPos:], 1)
+	}
+	return n, nil
+}
+
+// Contents returns a copy of the retained text, oldest first.  If the ring
+// has wrapped, the oldest line is omitted as it may be incomplete.
+func (w *MmapWriter) Contents() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.m == nil {
+		return nil
+	}
+	rv, _ := mmapContents(w.m)
+	return rv
+}
+
+// mmapContents extracts the retained text from a ring image.
+func mmapContents(m []byte) ([]byte, error) {
+	if len(m) <= MmapHeaderSize {
+		return nil, ErrInvalidMmapFile
+	}
+	data := m[MmapHeaderSize:]
+	off, wrapped, err := mmapHeader(m, len(data))
+	if err != nil {
+		return nil, err
+	}
+	if !wrapped {
+		return append([]byte(nil), data[:off]...), nil
+	}
+	rv := make([]byte, 0, len(data))
+	rv = append(rv, data[off:]...)
+	rv = append(rv, data[:off]...)
+	if i := bytes.IndexByte(rv, '\n'); i >= 0 {
+		rv = rv[i+1:]
+	} else {
+		rv = rv[:0]
+	}
+	return rv, nil
+}
+
+// ReadMmapFile returns the retained text of the ring in the file at path,
+// oldest first, as Contents would.  This allows the output of a previous
+// run to be recovered, e.g. after a crash.
+func ReadMmapFile(path string) ([]byte, error) {
+	m, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return mmapContents(m)
+}
+
+// Sync ensures the ring has been written to the file.
+func (w *MmapWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.m == nil {
+		return os.ErrClosed
+	}
+	return mmapFlush(w.f, w.m)
+}
+
+// Close writes the ring to the file, releases the mapping, and closes the
+// file, returning the first error encountered.
+func (w *MmapWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.m == nil {
+		return os.ErrClosed
+	}
+	err := mmapFlush(w.f, w.m)
+	if uerr := munmapFile(w.m); err == nil {
+		err = uerr
+	}
+	w.m = nil
+	w.data = nil
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// MmapLogMaker opens a MmapWriter on path with a total size of size bytes
+// and returns a LogMaker that creates LogLogger instances that write to it,
+// along with the writer so the application can read back its contents and
+// close it on shutdown.  Since LogLogger writes each message with a single
+// write, messages from different loggers are not interleaved.  The initial
+// priority is Warning.
+func MmapLogMaker(path string, size int64) (LogMaker, *MmapWriter, error) {
+	w, err := OpenMmapWriter(path, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(owner interface{}) Logger {
+		lgr := LogLogMaker(owner)
+		lgr.(*LogLogger).Instance().SetOutput(w)
+		return lgr
+	}, w, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package logwrap
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile emulates a mapping by reading the first size bytes of f into
+// memory.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	m := make([]byte, size)
+	if _, err := f.ReadAt(m, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return m, nil
+}
+
+// mmapFlush writes the emulated mapping m to f.
+func mmapFlush(f *os.File, m []byte) error {
+	if _, err := f.WriteAt(m, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// munmapFile releases the emulated mapping m.
+func munmapFile(m []byte) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMmapWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	if _, err := OpenMmapWriter(path, MmapHeaderSize); err != os.ErrInvalid {
+		t.Errorf("small size accepted: %v", err)
+	}
+
+	w, err := OpenMmapWriter(path, MmapHeaderSize+16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("line 1\n"))
+	_, _ = w.Write([]byte("line 2\n"))
+	if s := string(w.Contents()); s != "line 1\nline 2\n" {
+		t.Errorf("wrong contents: %q", s)
+	}
+
+	// Wraps within the third line; the partial first line is dropped.
+	_, _ = w.Write([]byte("line 3\n"))
+	if s := string(w.Contents()); s != "line 2\nline 3\n" {
+		t.Errorf("wrong wrapped contents: %q", s)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("closed\n")); err != os.ErrClosed {
+		t.Errorf("write after close: %v", err)
+	}
+
+	// Reopening resumes after the existing content.
+	w, err = OpenMmapWriter(path, MmapHeaderSize+16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("line 4\n"))
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if s := string(w.Contents()); s != "line 3\nline 4\n" {
+		t.Errorf("wrong resumed contents: %q", s)
+	}
+
+	// A write larger than the ring retains its end.
+	_, _ = w.Write([]byte("0123456789abcdef\nlast\n"))
+	if s := string(w.Contents()); s != "last\n" {
+		t.Errorf("wrong oversize contents: %q", s)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ReadMmapFile(path); err != nil || string(b) != "last\n" {
+		t.Errorf("wrong file contents: %q %v", b, err)
+	}
+
+	// A different size reinitializes the file.
+	w, err = OpenMmapWriter(path, MmapHeaderSize+64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := w.Contents(); len(b) != 0 {
+		t.Errorf("not reinitialized: %q", b)
+	}
+	_ = w.Close()
+
+	other := filepath.Join(t.TempDir(), "other")
+	_ = os.WriteFile(other, []byte(strings.Repeat("x", 64)), 0o644)
+	if _, err := ReadMmapFile(other); !errors.Is(err, ErrInvalidMmapFile) {
+		t.Errorf("invalid file accepted: %v", err)
+	}
+}
+
+func TestMmapLogMaker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	mk, w, err := MmapLogMaker(path, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr := mk(nil)
+	lgr.(*LogLogger).Instance().SetFlags(0)
+	lgr.SetId("m")
+	lgr.F(Error, "failed %d", 1)
+	lgr.F(Info, "filtered")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ReadMmapFile(path); err != nil || string(b) != "m[E] failed 1\n" {
+		t.Errorf("wrong contents: %q %v", b, err)
+	}
+}
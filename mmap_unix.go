@@ -0,0 +1,28 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package logwrap
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the first size bytes of f into memory, shared with the
+// file.
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// mmapFlush asks the system to write modified pages of the mapping m of f
+// to storage.
+func mmapFlush(f *os.File, m []byte) error {
+	return f.Sync()
+}
+
+// munmapFile releases the mapping m.
+func munmapFile(m []byte) error {
+	return syscall.Munmap(m)
+}
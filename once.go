@@ -0,0 +1,238 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultOnceLimit is the number of distinct messages remembered by an
+// OnceLogger when no limit is specified.
+const DefaultOnceLimit = 1024
+
+// OnceLogger wraps a Logger so that each distinct message is emitted only the
+// first time it is logged.  This eliminates repetitive noise from warnings
+// issued in a loop, without losing the first occurrence.
+//
+// Messages are distinguished by priority and formatted text, or optionally
+// by priority and format string.  Only messages that pass the priority
+// filter are remembered.  Memory is bounded: once the limit is reached the
+// oldest remembered message is forgotten to make room for a new one, so a
+// forgotten message would be emitted again if it recurs.  All methods are
+// safe for concurrent use if the wrapped logger is.
+type OnceLogger struct {
+	lgr      Logger
+	mu       sync.Mutex
+	byFormat bool
+	seen     map[string]struct{}
+	order    []string
+	next     int
+}
+
+// MakeOnceLogger returns an OnceLogger that forwards to lgr and remembers up
+// to limit distinct messages.  Values of limit less than 1 are replaced by
+// DefaultOnceLimit.
+func MakeOnceLogger(lgr Logger, limit int) *OnceLogger {
+	if limit < 1 {
+		limit = DefaultOnceLimit
+	}
+	return &OnceLogger{
+		lgr:   lgr,
+		seen:  make(map[string]struct{}),
+		order: make([]string, 0, limit),
+	}
+}
+
+// SetByFormat selects whether messages are distinguished by their format
+// string rather than their formatted text.  Comparing format strings avoids
+// formatting suppressed messages, and treats messages that differ only in
+// their arguments as duplicates.
+func (v *OnceLogger) SetByFormat(byFormat bool) *OnceLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.byFormat = byFormat
+	return v
+}
+
+// Reset forgets all previously seen messages.
+func (v *OnceLogger) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.seen = make(map[string]struct{})
+	v.order = v.order[:0]
+	v.next = 0
+}
+
+// firstSeen records key and returns true if it had not been seen before.
+// The caller must hold v.mu.
+func (v *OnceLogger) firstSeen(key string) bool {
+	if _, ok := v.seen[key]; ok {
+		return false
+	}
+	if len(v.order) < cap(v.order) {
+		v.order = append(v.order, key)
+	} else {
+		delete(v.seen, v.order[v.next])
+		v.order[v.next] = key
+		v.next = (v.next + 1) % len(v.order)
+	}
+	v.seen[key] = struct{}{}
+	return true
+}
+
+// Priority per ImmutableLogger.
+func (v *OnceLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *OnceLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.mu.Lock()
+	byFormat := v.byFormat
+	v.mu.Unlock()
+	msg := format
+	if !byFormat {
+		msg = fmt.Sprintf(format, args...)
+	}
+	v.mu.Lock()
+	first := v.firstSeen(priMap[pri] + msg)
+	v.mu.Unlock()
+	if !first {
+		return
+	}
+	if byFormat {
+		v.lgr.F(pri, format, args...)
+	} else {
+		v.lgr.F(pri, "%s", msg)
+	}
+}
+
+// Audit per Auditor.  Audit messages are not deduplicated.
+func (v *OnceLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *OnceLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *OnceLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
+
+// KeyedOnceLogger wraps a Logger so that messages submitted with the same
+// caller-supplied key are emitted at most once within a window.  This
+// suppresses repeats of an event whose messages differ in details that are
+// irrelevant for deduplication, such as timestamps or counters, which
+// defeats the text comparison of OnceLogger.
+//
+// Keyed messages are submitted with FKey; messages submitted with F are not
+// deduplicated.  Only messages that pass the priority filter are
+// remembered.  Memory is bounded: once the limit is reached the oldest
+// remembered key is forgotten to make room for a new one.  All methods are
+// safe for concurrent use if the wrapped logger is.
+type KeyedOnceLogger struct {
+	lgr    Logger
+	window time.Duration
+	mu     sync.Mutex
+	last   map[string]time.Time
+	order  []string
+	next   int
+}
+
+// MakeKeyedOnceLogger returns a KeyedOnceLogger that forwards to lgr,
+// suppresses repeats of a key for window after it was last emitted, and
+// remembers up to limit keys.  A window that is not positive suppresses
+// repeats until the key is forgotten.  Values of limit less than 1 are
+// replaced by DefaultOnceLimit.
+func MakeKeyedOnceLogger(lgr Logger, window time.Duration, limit int) *KeyedOnceLogger {
+	if limit < 1 {
+		limit = DefaultOnceLimit
+	}
+	return &KeyedOnceLogger{
+		lgr:    lgr,
+		window: window,
+		last:   make(map[string]time.Time),
+		order:  make([]string, 0, limit),
+	}
+}
+
+// Reset forgets all previously seen keys.
+func (v *KeyedOnceLogger) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.last = make(map[string]time.Time)
+	v.order = v.order[:0]
+	v.next = 0
+}
+
+// admit returns true if a message with key should be emitted at now,
+// recording the emission.  The caller must hold v.mu.
+func (v *KeyedOnceLogger) admit(key string, now time.Time) bool {
+	if t, ok := v.last[key]; ok {
+		if v.window <= 0 || now.Sub(t) < v.window {
+			return false
+		}
+	} else if len(v.order) < cap(v.order) {
+		v.order = append(v.order, key)
+	} else {
+		delete(v.last, v.order[v.next])
+		v.order[v.next] = key
+		v.next = (v.next + 1) % len(v.order)
+	}
+	v.last[key] = now
+	return true
+}
+
+// FKey emits a message through the wrapped logger unless a message with
+// the same key was emitted within the window.  Suppressed messages are not
+// formatted.  Keys are independent of priority.
+func (v *KeyedOnceLogger) FKey(key string, pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.mu.Lock()
+	ok := v.admit(key, time.Now())
+	v.mu.Unlock()
+	if ok {
+		v.lgr.F(pri, format, args...)
+	}
+}
+
+// Priority per ImmutableLogger.
+func (v *KeyedOnceLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  Messages submitted through F are not
+// deduplicated.
+func (v *KeyedOnceLogger) F(pri Priority, format string, args ...interface{}) {
+	v.lgr.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are not deduplicated.
+func (v *KeyedOnceLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *KeyedOnceLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *KeyedOnceLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,100 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnceLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeOnceLogger(newTestLogger(&sb), 2)
+
+	for i := 0; i < 3; i++ {
+		lgr.F(Warning, "disk %d%% full", 90)
+		lgr.F(Error, "disk %d%% full", 90)
+	}
+	if s := sb.String(); s != "[W] disk 90% full\n[E] disk 90% full\n" {
+		t.Errorf("wrong dedup: %q", s)
+	}
+	sb.Reset()
+
+	// Evicts the oldest (Warning) entry.
+	lgr.F(Info, "new")
+	lgr.F(Error, "disk %d%% full", 90)
+	lgr.F(Warning, "disk %d%% full", 90)
+	if s := sb.String(); s != "[I] new\n[W] disk 90% full\n" {
+		t.Errorf("wrong eviction: %q", s)
+	}
+	sb.Reset()
+
+	lgr.Reset()
+	lgr.F(Info, "new")
+	lgr.SetPriority(Warning)
+	lgr.F(Info, "filtered")
+	lgr.SetPriority(Debug)
+	lgr.F(Info, "filtered")
+	if s := sb.String(); s != "[I] new\n[I] filtered\n" {
+		t.Errorf("wrong reset: %q", s)
+	}
+	sb.Reset()
+
+	lgr.SetByFormat(true)
+	lgr.F(Info, "count %d", 1)
+	lgr.F(Info, "count %d", 2)
+	if s := sb.String(); s != "[I] count 1\n" {
+		t.Errorf("wrong by format: %q", s)
+	}
+
+	if MakeOnceLogger(lgr, 0).order == nil || cap(MakeOnceLogger(lgr, 0).order) != DefaultOnceLimit {
+		t.Errorf("default limit not applied")
+	}
+}
+
+func TestKeyedOnceLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeKeyedOnceLogger(newTestLogger(&sb), 0, 2)
+
+	for i := 0; i < 3; i++ {
+		lgr.FKey("conn", Warning, "connection lost after %d ms", 10*i)
+		lgr.F(Info, "plain %d", i)
+	}
+	exp := "[W] connection lost after 0 ms\n[I] plain 0\n[I] plain 1\n[I] plain 2\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong dedup: %q", s)
+	}
+	sb.Reset()
+
+	// Filtered messages are not remembered.
+	lgr.SetPriority(Warning)
+	lgr.FKey("dbg", Debug, "hidden")
+	lgr.SetPriority(Debug)
+	lgr.FKey("dbg", Debug, "shown")
+
+	// Evicts the oldest (conn) key.
+	lgr.FKey("other", Error, "other")
+	lgr.FKey("conn", Error, "again")
+	if s := sb.String(); s != "[D] shown\n[E] other\n[E] again\n" {
+		t.Errorf("wrong eviction: %q", s)
+	}
+	sb.Reset()
+
+	lgr.Reset()
+	lgr.FKey("conn", Error, "reset")
+	if s := sb.String(); s != "[E] reset\n" {
+		t.Errorf("wrong reset: %q", s)
+	}
+	sb.Reset()
+
+	wlgr := MakeKeyedOnceLogger(newTestLogger(&sb), 20*time.Millisecond, 0)
+	wlgr.FKey("k", Info, "first")
+	wlgr.FKey("k", Info, "suppressed")
+	time.Sleep(30 * time.Millisecond)
+	wlgr.FKey("k", Info, "expired")
+	if s := sb.String(); s != "[I] first\n[I] expired\n" {
+		t.Errorf("wrong window: %q", s)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// opSummaryNouns are the nouns used in the OpLogger summary, in the order
+// in which they appear.
+var opSummaryNouns = []struct {
+	pri  Priority
+	noun string
+}{
+	{Warning, "warning"},
+	{Error, "error"},
+	{Crit, "critical error"},
+	{Emerg, "emergency"},
+}
+
+// OpLogger wraps a Logger used for a single operation, such as a batch job,
+// and counts the messages submitted at each priority so that a concise
+// summary of the health of the operation can be emitted when it ends.
+//
+// The summary is emitted only when Done is invoked, so Done must be called
+// when the operation completes, typically with defer.  Messages are counted
+// whether or not the wrapped logger emits them.  All methods are safe for
+// concurrent use if the wrapped logger is.
+type OpLogger struct {
+	lgr    Logger
+	mu     sync.Mutex
+	counts map[Priority]uint64
+}
+
+// MakeOpLogger returns an OpLogger that forwards to lgr.
+func MakeOpLogger(lgr Logger) *OpLogger {
+	return &OpLogger{
+		lgr:    lgr,
+		counts: make(map[Priority]uint64),
+	}
+}
+
+// Counts returns the number of messages submitted at each priority.
+func (v *OpLogger) Counts() map[Priority]uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rv := make(map[Priority]uint64, len(v.counts))
+	for pri, n := range v.counts {
+		rv[pri] = n
+	}
+	return rv
+}
+
+// Done emits a summary of the operation, e.g. "operation complete: 3
+// warnings, 1 error", listing the non-zero counts of messages at Warning
+// and more severe priorities.  An operation with none is summarized as
+// "operation complete: no warnings or errors" at Notice; otherwise the
+// summary is emitted at Warning.  Each call emits a summary of the counts
+// accumulated so far.
+func (v *OpLogger) Done() {
+	counts := v.Counts()
+	var parts []string
+	for _, sn := range opSummaryNouns {
+		if n := counts[sn.pri]; n > 0 {
+			s := strconv.FormatUint(n, 10) + " " + sn.noun
+			if n > 1 {
+				s += "s"
+			}
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		v.lgr.F(Notice, "operation complete: no warnings or errors")
+		return
+	}
+	v.lgr.F(Warning, "operation complete: %s", strings.Join(parts, ", "))
+}
+
+// Priority per ImmutableLogger.
+func (v *OpLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *OpLogger) F(pri Priority, format string, args ...interface{}) {
+	v.mu.Lock()
+	v.counts[pri]++
+	v.mu.Unlock()
+	v.lgr.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are not counted.
+func (v *OpLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *OpLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *OpLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,40 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpLogger(t *testing.T) {
+	var sb strings.Builder
+	blgr := newTestLogger(&sb)
+
+	lgr := MakeOpLogger(blgr)
+	lgr.F(Info, "working")
+	lgr.Done()
+	if s := sb.String(); s != "[I] working\n[N] operation complete: no warnings or errors\n" {
+		t.Errorf("wrong clean summary: %q", s)
+	}
+	sb.Reset()
+
+	lgr = MakeOpLogger(blgr)
+	blgr.SetPriority(Error)
+	for i := 0; i < 3; i++ {
+		lgr.F(Warning, "hidden %d", i)
+	}
+	lgr.F(Error, "failed")
+	lgr.F(Crit, "broken")
+	blgr.SetPriority(Debug)
+	lgr.Done()
+	exp := "[E] failed\n[C] broken\n" +
+		"[W] operation complete: 3 warnings, 1 error, 1 critical error\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong summary: %q", s)
+	}
+	if n := lgr.Counts()[Warning]; n != 3 {
+		t.Errorf("wrong warning count: %d", n)
+	}
+}
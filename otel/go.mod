@@ -0,0 +1,19 @@
+module github.com/pabigot/logwrap/otel
+
+go 1.23.0
+
+require (
+	github.com/pabigot/logwrap v0.3.0
+	go.opentelemetry.io/otel/log v0.13.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel v1.37.0 // indirect
+	go.opentelemetry.io/otel/metric v1.37.0 // indirect
+	go.opentelemetry.io/otel/trace v1.37.0 // indirect
+)
+
+replace github.com/pabigot/logwrap => ../
@@ -0,0 +1,115 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otel provides a logwrap.Logger that emits messages as
+// OpenTelemetry log records.  It is a separate module so that applications
+// that do not use OpenTelemetry do not depend on it.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lw "github.com/pabigot/logwrap"
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// IdAttribute is the key of the record attribute that holds the identifier
+// assigned with SetId.  Records from loggers with no identifier do not have
+// the attribute.
+const IdAttribute = "id"
+
+var severityMap = map[lw.Priority]otellog.Severity{
+	lw.Emerg:   otellog.SeverityFatal4,
+	lw.Crit:    otellog.SeverityFatal1,
+	lw.Error:   otellog.SeverityError1,
+	lw.Warning: otellog.SeverityWarn1,
+	lw.Notice:  otellog.SeverityInfo2,
+	lw.Info:    otellog.SeverityInfo1,
+	lw.Debug:   otellog.SeverityDebug1,
+}
+
+// Severity returns the OpenTelemetry severity number used for records
+// emitted at pri.  Notice maps to INFO2 to retain its precedence over Info,
+// and Emerg maps to FATAL4 to retain its precedence over Crit.
+func Severity(pri lw.Priority) otellog.Severity {
+	return severityMap[pri]
+}
+
+// Logger emits logwrap messages as records through an OpenTelemetry
+// logger.  All methods are safe for concurrent use.
+type Logger struct {
+	ol  otellog.Logger
+	mu  sync.Mutex
+	id  string
+	pri lw.Priority
+}
+
+// OTelLogMaker returns a LogMaker that creates loggers emitting records
+// through ol.  The record body is the formatted message, the severity
+// number is derived from the message priority by Severity, and the severity
+// text is the priority name.  Created loggers have priority Warning.
+func OTelLogMaker(ol otellog.Logger) lw.LogMaker {
+	return func(interface{}) lw.Logger {
+		return &Logger{
+			ol:  ol,
+			pri: lw.Warning,
+		}
+	}
+}
+
+// Priority per logwrap.ImmutableLogger.
+func (v *Logger) Priority() lw.Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pri
+}
+
+// F per logwrap.ImmutableLogger.  Messages are dropped without formatting if
+// the OpenTelemetry logger reports it will not emit them.
+func (v *Logger) F(pri lw.Priority, format string, args ...interface{}) {
+	v.mu.Lock()
+	id, enabled := v.id, v.pri.Enables(pri)
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	ctx := context.Background()
+	sev := Severity(pri)
+	if !v.ol.Enabled(ctx, otellog.EnabledParameters{Severity: sev}) {
+		return
+	}
+	var r otellog.Record
+	r.SetTimestamp(time.Now())
+	r.SetSeverity(sev)
+	r.SetSeverityText(pri.String())
+	r.SetBody(otellog.StringValue(fmt.Sprintf(format, args...)))
+	if id != "" {
+		r.AddAttributes(otellog.String(IdAttribute, id))
+	}
+	v.ol.Emit(ctx, r)
+}
+
+// SetId per logwrap.Logger.  The id is attached to records as an attribute
+// rather than being added to the message.
+func (v *Logger) SetId(id string) lw.Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.id = id
+	return v
+}
+
+// SetPriority per logwrap.Logger.
+func (v *Logger) SetPriority(pri lw.Priority) lw.Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
+
+// Instance provides access to the OpenTelemetry logger.
+func (v *Logger) Instance() otellog.Logger {
+	return v.ol
+}
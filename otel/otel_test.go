@@ -0,0 +1,78 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package otel
+
+import (
+	"context"
+	"testing"
+
+	lw "github.com/pabigot/logwrap"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+)
+
+type recorder struct {
+	embedded.Logger
+	floor   otellog.Severity
+	records []otellog.Record
+}
+
+func (r *recorder) Emit(ctx context.Context, rec otellog.Record) {
+	r.records = append(r.records, rec)
+}
+
+func (r *recorder) Enabled(ctx context.Context, param otellog.EnabledParameters) bool {
+	return param.Severity >= r.floor
+}
+
+func TestOTelLogger(t *testing.T) {
+	rec := &recorder{}
+	lgr := OTelLogMaker(rec)(nil)
+	if p := lgr.Priority(); p != lw.Warning {
+		t.Errorf("wrong initial priority: %s", p)
+	}
+
+	lgr.F(lw.Error, "err %d", 1)
+	lgr.F(lw.Info, "dropped")
+	lgr.SetId("svc").SetPriority(lw.Debug)
+	lgr.F(lw.Notice, "note")
+	rec.floor = otellog.SeverityInfo1
+	lgr.F(lw.Debug, "dropped")
+
+	if n := len(rec.records); n != 2 {
+		t.Fatalf("wrong record count: %d", n)
+	}
+	r := rec.records[0]
+	if r.Severity() != otellog.SeverityError1 || r.SeverityText() != "Error" || r.Body().AsString() != "err 1" || r.AttributesLen() != 0 {
+		t.Errorf("wrong first record: %v %s %s", r.Severity(), r.SeverityText(), r.Body())
+	}
+	if r.Timestamp().IsZero() {
+		t.Errorf("no timestamp")
+	}
+	r = rec.records[1]
+	if r.Severity() != otellog.SeverityInfo2 || r.Body().AsString() != "note" {
+		t.Errorf("wrong second record: %v %s", r.Severity(), r.Body())
+	}
+	var id string
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		if kv.Key == IdAttribute {
+			id = kv.Value.AsString()
+		}
+		return true
+	})
+	if id != "svc" {
+		t.Errorf("wrong id attribute: %q", id)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	prev := otellog.SeverityUndefined
+	for _, pri := range []lw.Priority{lw.Debug, lw.Info, lw.Notice, lw.Warning, lw.Error, lw.Crit, lw.Emerg} {
+		sev := Severity(pri)
+		if sev <= prev {
+			t.Errorf("%s severity %v not above %v", pri, sev, prev)
+		}
+		prev = sev
+	}
+}
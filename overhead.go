@@ -0,0 +1,79 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"time"
+)
+
+// overheadStats accumulates the time LogLogger spends on messages for
+// overhead reports.
+type overheadStats struct {
+	every    int
+	interval time.Duration
+	start    time.Time
+	n        int
+	format   time.Duration
+	write    time.Duration
+}
+
+// SetOverheadReport enables periodic reports of the time the logger spends
+// formatting and writing messages emitted with F, to help decide whether
+// logging is a performance bottleneck.  A report is emitted at Debug after
+// every messages, or once interval has elapsed since the previous report,
+// whichever comes first, e.g. "logwrap overhead: 1000 messages, format avg
+// 1.2µs, write avg 3.4µs".  A non-positive every or interval disables that
+// trigger; if both are non-positive reporting is disabled, which is the
+// default.
+//
+// This is a diagnostic aid, not meant to be left enabled.  Measurement
+// reads the clock four times per message, which is small relative to
+// formatting and writing but not free.  Reports are not themselves
+// measured, and are discarded if Debug is not enabled when they are due.
+func (v *LogLogger) SetOverheadReport(every int, interval time.Duration) *LogLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if every <= 0 && interval <= 0 {
+		v.overhead = nil
+	} else {
+		v.overhead = &overheadStats{
+			every:    every,
+			interval: interval,
+			start:    time.Now(),
+		}
+	}
+	return v
+}
+
+// measuredOutput emits a message from F that was formatted between t0 and
+// t1, accounting for the time spent and emitting an overhead report when
+// one is due.  The caller must hold v.mu.
+func (v *LogLogger) measuredOutput(t0, t1 time.Time, pri Priority, s string) error {
+	tw := time.Now()
+	err := v.output(tw, 3, pri, s)
+	t2 := time.Now()
+
+	oh := v.overhead
+	oh.n++
+	oh.format += t1.Sub(t0)
+	oh.write += t2.Sub(tw)
+	if (oh.every > 0 && oh.n >= oh.every) ||
+		(oh.interval > 0 && t2.Sub(oh.start) >= oh.interval) {
+		n := time.Duration(oh.n)
+		msg := fmt.Sprintf("logwrap overhead: %d messages, format avg %s, write avg %s",
+			oh.n, oh.format/n, oh.write/n)
+		*oh = overheadStats{
+			every:    oh.every,
+			interval: oh.interval,
+			start:    t2,
+		}
+		if v.priority().Enables(Debug) {
+			if rerr := v.output(t2, 3, Debug, msg); err == nil {
+				err = rerr
+			}
+		}
+	}
+	return err
+}
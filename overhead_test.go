@@ -0,0 +1,50 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogLoggerOverheadReport(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	ll := lgr.(*LogLogger)
+
+	ll.SetOverheadReport(3, 0)
+	for i := 0; i < 7; i++ {
+		lgr.F(Info, "m%d", i)
+	}
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	re := regexp.MustCompile(`^\[D\] logwrap overhead: 3 messages, format avg \S+, write avg \S+$`)
+	if len(lines) != 9 || !re.MatchString(lines[3]) || !re.MatchString(lines[7]) ||
+		lines[8] != "[I] m6" {
+		t.Errorf("wrong count output: %q", lines)
+	}
+	sb.Reset()
+
+	ll.SetOverheadReport(0, 10*time.Millisecond)
+	lgr.F(Info, "early")
+	time.Sleep(10 * time.Millisecond)
+	lgr.F(Info, "late")
+	re = regexp.MustCompile(`^\[I\] early\n\[I\] late\n\[D\] logwrap overhead: 2 messages, .*\n$`)
+	if s := sb.String(); !re.MatchString(s) {
+		t.Errorf("wrong interval output: %q", s)
+	}
+	sb.Reset()
+
+	// Reports are dropped if Debug is disabled.
+	ll.SetOverheadReport(1, 0)
+	lgr.SetPriority(Info)
+	lgr.F(Info, "quiet")
+	ll.SetOverheadReport(0, 0)
+	lgr.SetPriority(Debug)
+	lgr.F(Info, "off")
+	if s := sb.String(); s != "[I] quiet\n[I] off\n" {
+		t.Errorf("wrong disabled output: %q", s)
+	}
+}
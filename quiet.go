@@ -0,0 +1,96 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync"
+	"time"
+)
+
+// QuietLogger wraps a Logger to temporarily suppress messages that are less
+// severe than a floor priority.  This reduces noise during scheduled
+// operations that are known to produce many routine complaints, without
+// changing the priority of the underlying logger.
+//
+// The quiet window is expressed as an absolute deadline.  Once it passes,
+// messages are again filtered only by the underlying logger.  All methods
+// are safe for concurrent use if the underlying logger is.
+type QuietLogger struct {
+	lgr   Logger
+	mu    sync.Mutex
+	until time.Time
+	floor Priority
+}
+
+// MakeQuietLogger returns a QuietLogger that forwards messages to lgr.  It
+// is not initially quiet.
+func MakeQuietLogger(lgr Logger) *QuietLogger {
+	return &QuietLogger{
+		lgr: lgr,
+	}
+}
+
+// QuietUntil suppresses messages less severe than floor until t.  For
+// example QuietUntil(t, Error) passes only Error and more severe messages
+// until t.  A floor less restrictive than the underlying logger's priority
+// has no effect.  Invoking QuietUntil replaces any previous window; a t that
+// is not after the current time ends the window immediately.
+func (v *QuietLogger) QuietUntil(t time.Time, floor Priority) *QuietLogger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.until = t
+	v.floor = floor
+	return v
+}
+
+// quietFloor returns the floor in effect, or an unset priority if the
+// logger is not quiet.
+func (v *QuietLogger) quietFloor() Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.until.IsZero() {
+		return unsetPriority
+	}
+	if !time.Now().Before(v.until) {
+		v.until = time.Time{}
+		return unsetPriority
+	}
+	return v.floor
+}
+
+// Priority per ImmutableLogger.  While quiet this is the more restrictive
+// of the floor and the underlying logger's priority.
+func (v *QuietLogger) Priority() Priority {
+	pri := v.lgr.Priority()
+	if floor := v.quietFloor(); floor.IsSet() && floor < pri {
+		pri = floor
+	}
+	return pri
+}
+
+// F per ImmutableLogger.
+func (v *QuietLogger) F(pri Priority, format string, args ...interface{}) {
+	if floor := v.quietFloor(); floor.IsSet() && !floor.Enables(pri) {
+		return
+	}
+	v.lgr.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are emitted during a quiet window.
+func (v *QuietLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *QuietLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.  This changes the underlying logger's priority
+// and does not affect any quiet window.
+func (v *QuietLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,46 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuietLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeQuietLogger(newTestLogger(&sb))
+
+	if p := lgr.Priority(); p != Debug {
+		t.Errorf("wrong initial priority: %s", p)
+	}
+	lgr.F(Info, "loud")
+
+	lgr.QuietUntil(time.Now().Add(time.Hour), Error)
+	if p := lgr.Priority(); p != Error {
+		t.Errorf("wrong quiet priority: %s", p)
+	}
+	lgr.F(Warning, "hushed")
+	lgr.F(Crit, "heard")
+
+	// A window in the past ends quiet immediately.
+	lgr.QuietUntil(time.Now().Add(-time.Second), Error)
+	if p := lgr.Priority(); p != Debug {
+		t.Errorf("wrong resumed priority: %s", p)
+	}
+	lgr.F(Info, "resumed")
+
+	// A permissive floor does not override the logger.
+	lgr.SetPriority(Warning)
+	lgr.QuietUntil(time.Now().Add(time.Hour), Debug)
+	if p := lgr.Priority(); p != Warning {
+		t.Errorf("wrong permissive priority: %s", p)
+	}
+
+	exp := "[I] loud\n[C] heard\n[I] resumed\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+}
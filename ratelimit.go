@@ -0,0 +1,267 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit bounds the number of messages emitted within each interval.
+type RateLimit struct {
+	// Count is the number of messages that may be emitted in each
+	// interval.  A value less than 1 suppresses all messages.
+	Count int
+	// Interval is the length of the window over which Count applies.  A
+	// limit with an Interval that is not positive has no effect.
+	Interval time.Duration
+}
+
+// rateBudget tracks use of a RateLimit within its current window.
+type rateBudget struct {
+	start   time.Time
+	used    int
+	dropped int
+}
+
+// RateLimitLogger wraps a Logger to limit the rate at which messages are
+// emitted, with an independent limit for each priority.  This allows
+// chatty Debug messages to be throttled heavily while Error messages are
+// barely limited and Emerg messages are never dropped.  Priorities without
+// a limit are not throttled.
+//
+// Limits are applied in fixed windows that begin with the first message at
+// the priority after the previous window ended.  When messages were dropped
+// in a window, a summary message at the same priority reports the number
+// suppressed.  The summary is emitted ahead of the first message admitted
+// in a subsequent window, or by Flush, and is not itself subject to the
+// limit.  All methods are safe for concurrent use if the wrapped logger is.
+type RateLimitLogger struct {
+	lgr     Logger
+	mu      sync.Mutex
+	limits  map[Priority]RateLimit
+	budgets map[Priority]*rateBudget
+	total   map[Priority]uint64
+}
+
+// MakeRateLimitLogger returns a RateLimitLogger that forwards to lgr
+// subject to limits.  The map is copied; changing it after the call has no
+// effect.  A limit for Emerg is ignored, as Emerg messages are never
+// dropped.
+func MakeRateLimitLogger(lgr Logger, limits map[Priority]RateLimit) *RateLimitLogger {
+	v := &RateLimitLogger{
+		lgr:     lgr,
+		limits:  make(map[Priority]RateLimit, len(limits)),
+		budgets: make(map[Priority]*rateBudget, len(limits)),
+		total:   make(map[Priority]uint64),
+	}
+	for pri, lim := range limits {
+		if pri != Emerg && lim.Interval > 0 {
+			v.limits[pri] = lim
+			v.budgets[pri] = &rateBudget{}
+		}
+	}
+	return v
+}
+
+// Suppressed returns the number of messages dropped at each priority since
+// the logger was created.  Priorities with no drops are not present.
+func (v *RateLimitLogger) Suppressed() map[Priority]uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rv := make(map[Priority]uint64, len(v.total))
+	for pri, n := range v.total {
+		rv[pri] = n
+	}
+	return rv
+}
+
+// Flush emits the suppression summary for any priority that has dropped
+// messages in its current window, and starts a new window for them.  This
+// should be invoked before shutdown so drops at the end of a run are
+// reported.
+func (v *RateLimitLogger) Flush() {
+	v.mu.Lock()
+	var pending []Priority
+	var counts []int
+	for pri := Emerg; pri <= Debug; pri++ {
+		if b := v.budgets[pri]; b != nil && b.dropped != 0 {
+			pending = append(pending, pri)
+			counts = append(counts, b.dropped)
+			*b = rateBudget{}
+		}
+	}
+	v.mu.Unlock()
+	for i, pri := range pending {
+		v.summarize(pri, counts[i])
+	}
+}
+
+// admit determines whether a message at pri may be emitted at now.  If a
+// previous window ended with drops their count is returned so the caller
+// can summarize them.  The caller must hold v.mu.
+func (v *RateLimitLogger) admit(pri Priority, now time.Time) (ok bool, dropped int) {
+	b := v.budgets[pri]
+	if b == nil {
+		return true, 0
+	}
+	lim := v.limits[pri]
+	if b.start.IsZero() || now.Sub(b.start) >= lim.Interval {
+		dropped = b.dropped
+		*b = rateBudget{start: now}
+	}
+	if b.used < lim.Count {
+		b.used++
+		return true, dropped
+	}
+	b.dropped++
+	v.total[pri]++
+	return false, dropped
+}
+
+// summarize emits the summary of n messages dropped at pri.
+func (v *RateLimitLogger) summarize(pri Priority, n int) {
+	v.lgr.F(pri, "rate limit suppressed %d %s messages", n, pri)
+}
+
+// Priority per ImmutableLogger.
+func (v *RateLimitLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  Messages are formatted only if they are admitted
+// by both the underlying logger and the limit for pri.
+func (v *RateLimitLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.mu.Lock()
+	ok, dropped := v.admit(pri, time.Now())
+	v.mu.Unlock()
+	if dropped != 0 {
+		v.summarize(pri, dropped)
+	}
+	if ok {
+		v.lgr.F(pri, format, args...)
+	}
+}
+
+// Audit per Auditor.  Audit messages are not rate limited.
+func (v *RateLimitLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *RateLimitLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *RateLimitLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
+
+// GlobalRateLimitLogger wraps a Logger to cap the total rate at which
+// messages are emitted across all priorities, protecting a shared collector
+// regardless of how many subsystems are logging.  The cap is a token
+// bucket: each emitted message consumes a token, tokens are replenished
+// continuously at a fixed rate, and up to a burst of tokens accumulate
+// while output is idle.  Messages that arrive when no token is available
+// are dropped and counted.
+//
+// Messages at Emerg are never dropped and do not consume tokens, as they
+// report that the system is unusable.  Messages filtered by the wrapped
+// logger do not consume tokens.  All methods are safe for concurrent use if
+// the wrapped logger is.
+type GlobalRateLimitLogger struct {
+	lgr     Logger
+	rate    float64
+	burst   float64
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	dropped uint64
+}
+
+// MakeGlobalRateLimitLogger returns a GlobalRateLimitLogger that forwards
+// to lgr at most perSec messages per second on average, with bursts of up
+// to burst messages.  The bucket is initially full.  Values of perSec and
+// burst less than 1 are replaced by 1.
+func MakeGlobalRateLimitLogger(lgr Logger, perSec int, burst int) *GlobalRateLimitLogger {
+	if perSec < 1 {
+		perSec = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &GlobalRateLimitLogger{
+		lgr:    lgr,
+		rate:   float64(perSec),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Dropped returns the number of messages dropped because no token was
+// available.
+func (v *GlobalRateLimitLogger) Dropped() uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.dropped
+}
+
+// take consumes a token if one is available at now.
+func (v *GlobalRateLimitLogger) take(now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if dt := now.Sub(v.last); dt > 0 {
+		v.tokens += dt.Seconds() * v.rate
+		if v.tokens > v.burst {
+			v.tokens = v.burst
+		}
+		v.last = now
+	}
+	if v.tokens < 1 {
+		v.dropped++
+		return false
+	}
+	v.tokens--
+	return true
+}
+
+// Priority per ImmutableLogger.
+func (v *GlobalRateLimitLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  Dropped messages are not formatted.
+func (v *GlobalRateLimitLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	if pri != Emerg && !v.take(time.Now()) {
+		return
+	}
+	v.lgr.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are not rate limited.
+func (v *GlobalRateLimitLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *GlobalRateLimitLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *GlobalRateLimitLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,111 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeRateLimitLogger(newTestLogger(&sb), map[Priority]RateLimit{
+		Debug: {Count: 1, Interval: time.Hour},
+		Error: {Count: 2, Interval: 20 * time.Millisecond},
+		Info:  {Count: 1},
+		// Emerg is never throttled.
+		Emerg: {Count: 1, Interval: time.Hour},
+	})
+
+	for i := 0; i < 3; i++ {
+		lgr.F(Debug, "d%d", i)
+		lgr.F(Info, "i%d", i)
+		lgr.F(Error, "e%d", i)
+		lgr.F(Emerg, "x%d", i)
+	}
+	exp := "[D] d0\n[I] i0\n[E] e0\n[!] x0\n" +
+		"[I] i1\n[E] e1\n[!] x1\n" +
+		"[I] i2\n[!] x2\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong limited output: %q", s)
+	}
+	sb.Reset()
+
+	time.Sleep(30 * time.Millisecond)
+	lgr.F(Error, "e3")
+	exp = "[E] rate limit suppressed 1 Error messages\n[E] e3\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong summary: %q", s)
+	}
+	sb.Reset()
+
+	lgr.Flush()
+	if s := sb.String(); s != "[D] rate limit suppressed 2 Debug messages\n" {
+		t.Errorf("wrong flush: %q", s)
+	}
+	sb.Reset()
+	lgr.Flush()
+	if s := sb.String(); s != "" {
+		t.Errorf("repeated flush: %q", s)
+	}
+
+	sup := lgr.Suppressed()
+	if len(sup) != 2 || sup[Debug] != 2 || sup[Error] != 1 {
+		t.Errorf("wrong suppressed counts: %v", sup)
+	}
+
+	// Filtered messages do not consume budget.
+	lgr.SetPriority(Info)
+	lgr.F(Debug, "filtered")
+	lgr.SetPriority(Debug)
+	lgr.F(Debug, "d3")
+	if s := sb.String(); s != "[D] d3\n" {
+		t.Errorf("wrong post-flush output: %q", s)
+	}
+}
+
+func TestGlobalRateLimitLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeGlobalRateLimitLogger(newTestLogger(&sb), 50, 3)
+
+	for i := 0; i < 4; i++ {
+		lgr.F(Info, "i%d", i)
+		lgr.F(Emerg, "x%d", i)
+	}
+	exp := "[I] i0\n[!] x0\n[I] i1\n[!] x1\n[I] i2\n[!] x2\n[!] x3\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong burst output: %q", s)
+	}
+	if n := lgr.Dropped(); n != 1 {
+		t.Errorf("wrong drop count: %d", n)
+	}
+	sb.Reset()
+
+	// Tokens replenish at 50/s.
+	time.Sleep(30 * time.Millisecond)
+	lgr.F(Error, "refilled")
+	if s := sb.String(); s != "[E] refilled\n" {
+		t.Errorf("wrong refill output: %q", s)
+	}
+	sb.Reset()
+
+	// Filtered messages do not consume tokens.
+	lgr.SetPriority(Warning)
+	for i := 0; i < 10; i++ {
+		lgr.F(Info, "filtered")
+	}
+	if n := lgr.Dropped(); n != 1 {
+		t.Errorf("filtered messages counted: %d", n)
+	}
+}
+
+func BenchmarkGlobalRateLimitLoggerParallel(b *testing.B) {
+	lgr := MakeGlobalRateLimitLogger(NullLogMaker(nil).SetPriority(Debug), 1000, 100)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lgr.F(Info, "message")
+		}
+	})
+}
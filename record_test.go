@@ -0,0 +1,45 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordLogMaker(t *testing.T) {
+	var recs []Record
+	lgr := RecordLogMaker(func(r Record) {
+		recs = append(recs, r)
+	})(nil)
+
+	if p := lgr.Priority(); p != Warning {
+		t.Errorf("wrong initial priority: %s", p)
+	}
+	lgr.F(Info, "dropped")
+	t0 := time.Now()
+	lgr.SetId("rec").F(Error, "failed %d", 3)
+	fields := Fields{"k": 1, "Token": "t0k"}
+	FF(lgr, Warning, fields, "with fields")
+	fields["k"] = 2
+
+	if len(recs) != 2 {
+		t.Fatalf("wrong record count: %d", len(recs))
+	}
+	r := recs[0]
+	if r.Priority != Error || r.Id != "rec" || r.Message != "failed 3" || r.Fields != nil {
+		t.Errorf("wrong record: %+v", r)
+	}
+	if r.Time.Before(t0) || time.Since(r.Time) > time.Second {
+		t.Errorf("wrong time: %s", r.Time)
+	}
+	r = recs[1]
+	if r.Priority != Warning || r.Message != "with fields" || len(r.Fields) != 2 {
+		t.Errorf("wrong record: %+v", r)
+	} else if v := r.Fields["k"]; v != 1 {
+		t.Errorf("fields not copied: %v", v)
+	} else if v := r.Fields["Token"]; v != MaskedValue {
+		t.Errorf("sensitive field not masked: %v", v)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// reportEntry is a message held by ReportLogger until it is flushed.
+type reportEntry struct {
+	pri Priority
+	msg string
+}
+
+// ReportLogger wraps a Logger to hold messages until Flush is called, then
+// emit them grouped by priority, most severe first.  Within a priority
+// messages are emitted in the order they were logged.  This suits command
+// line tools that summarize their results at completion, where a report
+// listing all errors, then all warnings, then everything else reads better
+// than chronological interleaving.
+//
+// Messages are formatted when they are logged, and only if the wrapped
+// logger would emit them, but the text of every retained message stays in
+// memory until the next Flush.  Memory use therefore grows without bound
+// with the number and size of messages logged, so a ReportLogger should
+// not be used for long-running processes or high-volume output.  Messages
+// that have not been flushed are lost if the process exits.
+//
+// All methods are safe for concurrent use if the wrapped logger is.
+type ReportLogger struct {
+	lgr     Logger
+	mu      sync.Mutex
+	entries []reportEntry
+}
+
+// MakeReportLogger returns a ReportLogger that forwards messages to lgr
+// when flushed.
+func MakeReportLogger(lgr Logger) *ReportLogger {
+	return &ReportLogger{
+		lgr: lgr,
+	}
+}
+
+// Len returns the number of messages waiting to be flushed.
+func (v *ReportLogger) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.entries)
+}
+
+// Flush emits all retained messages to the wrapped logger, most severe
+// priority first, and releases them.  Messages at priorities the wrapped
+// logger no longer enables are discarded.
+func (v *ReportLogger) Flush() {
+	v.mu.Lock()
+	entries := v.entries
+	v.entries = nil
+	v.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].pri < entries[j].pri
+	})
+	for _, e := range entries {
+		v.lgr.F(e.pri, "%s", e.msg)
+	}
+}
+
+// Priority per ImmutableLogger.
+func (v *ReportLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  The message is retained until the next Flush.
+func (v *ReportLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	e := reportEntry{
+		pri: pri,
+		msg: fmt.Sprintf(format, args...),
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.entries = append(v.entries, e)
+}
+
+// Audit per Auditor.  Audit messages are emitted immediately rather than
+// retained until Flush.
+func (v *ReportLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+}
+
+// SetId per Logger.
+func (v *ReportLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.  Retained messages are emitted through the
+// wrapped logger when flushed, so they are filtered again by the priority
+// in effect at that time: retained messages that it does not enable are
+// discarded.
+func (v *ReportLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
@@ -0,0 +1,57 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeReportLogger(newTestLogger(&sb))
+	lgr.SetPriority(Info)
+
+	lgr.F(Info, "info %d", 1)
+	lgr.F(Warning, "warning %d", 1)
+	lgr.F(Debug, "filtered")
+	lgr.F(Error, "error %d", 1)
+	lgr.F(Info, "info %d", 2)
+	lgr.F(Warning, "warning %d", 2)
+	if n := lgr.Len(); n != 5 {
+		t.Errorf("wrong length: %d", n)
+	}
+	if sb.Len() != 0 {
+		t.Errorf("emitted before flush: %q", sb.String())
+	}
+
+	lgr.Flush()
+	exp := "[E] error 1\n" +
+		"[W] warning 1\n" +
+		"[W] warning 2\n" +
+		"[I] info 1\n" +
+		"[I] info 2\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong report:\n%s", s)
+	}
+	if n := lgr.Len(); n != 0 {
+		t.Errorf("not released: %d", n)
+	}
+
+	sb.Reset()
+	lgr.Flush()
+	if sb.Len() != 0 {
+		t.Errorf("flush repeated: %q", sb.String())
+	}
+
+	// Retained messages are filtered by the priority at the time of the
+	// flush.
+	lgr.F(Info, "info %d", 3)
+	lgr.F(Error, "error %d", 2)
+	lgr.SetPriority(Error)
+	lgr.Flush()
+	if s := sb.String(); s != "[E] error 2\n" {
+		t.Errorf("wrong filtered report: %q", s)
+	}
+}
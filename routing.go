@@ -0,0 +1,110 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"sync"
+)
+
+// RoutingLogger is a Logger that forwards each message to every one of a set
+// of sinks whose floor enables the message priority.  For example a file
+// sink can capture everything down to Debug while an alerting sink receives
+// only Crit and Emerg, all from a single logger.
+//
+// The aggregate also has its own priority, which is Debug unless changed
+// with SetPriority.  Messages must be enabled by both the aggregate priority
+// and a sink's floor to reach that sink.  Priority reports the least
+// restrictive priority at which some sink will emit a message.
+//
+// All methods are safe for concurrent use if the sinks are.
+type RoutingLogger struct {
+	mu    sync.RWMutex
+	pri   Priority
+	sinks []routingSink
+}
+
+type routingSink struct {
+	lgr   Logger
+	floor Priority
+}
+
+// MakeRoutingLogger returns a RoutingLogger with no sinks.
+func MakeRoutingLogger() *RoutingLogger {
+	return &RoutingLogger{
+		pri: Debug,
+	}
+}
+
+// AddSink adds lgr as a sink that receives messages enabled by floor.  The
+// priority of lgr is set to floor so that it does not filter messages the
+// aggregate forwards to it.
+func (v *RoutingLogger) AddSink(lgr Logger, floor Priority) *RoutingLogger {
+	lgr.SetPriority(floor)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.sinks = append(v.sinks, routingSink{
+		lgr:   lgr,
+		floor: floor,
+	})
+	return v
+}
+
+// Priority per ImmutableLogger.
+func (v *RoutingLogger) Priority() Priority {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	rv := unsetPriority
+	for _, s := range v.sinks {
+		if s.floor > rv {
+			rv = s.floor
+		}
+	}
+	if v.pri < rv {
+		rv = v.pri
+	}
+	return rv
+}
+
+// F per ImmutableLogger.
+func (v *RoutingLogger) F(pri Priority, format string, args ...interface{}) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if !v.pri.Enables(pri) {
+		return
+	}
+	for _, s := range v.sinks {
+		if s.floor.Enables(pri) {
+			s.lgr.F(pri, format, args...)
+		}
+	}
+}
+
+// Audit per Auditor.  Audit messages are passed to every sink,
+// regardless of the aggregate priority and sink floors.
+func (v *RoutingLogger) Audit(format string, args ...interface{}) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, s := range v.sinks {
+		Audit(s.lgr, format, args...)
+	}
+}
+
+// SetId per Logger.  The identifier is assigned to every sink.
+func (v *RoutingLogger) SetId(id string) Logger {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, s := range v.sinks {
+		s.lgr.SetId(id)
+	}
+	return v
+}
+
+// SetPriority per Logger.  This sets the aggregate priority; sink floors
+// are not changed.
+func (v *RoutingLogger) SetPriority(pri Priority) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
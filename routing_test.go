@@ -0,0 +1,47 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoutingLogger(t *testing.T) {
+	var file, alert strings.Builder
+	lgr := MakeRoutingLogger()
+	if p := lgr.Priority(); p.IsSet() {
+		t.Errorf("empty priority set: %s", p)
+	}
+	lgr.AddSink(newTestLogger(&file), Debug).
+		AddSink(newTestLogger(&alert), Crit).
+		SetId("id ")
+	if p := lgr.Priority(); p != Debug {
+		t.Errorf("wrong priority: %s", p)
+	}
+
+	lgr.F(Debug, "debug")
+	lgr.F(Emerg, "emerg")
+	if s := file.String(); s != "id [D] debug\nid [!] emerg\n" {
+		t.Errorf("wrong file: %q", s)
+	}
+	if s := alert.String(); s != "id [!] emerg\n" {
+		t.Errorf("wrong alert: %q", s)
+	}
+	file.Reset()
+	alert.Reset()
+
+	lgr.SetPriority(Warning)
+	if p := lgr.Priority(); p != Warning {
+		t.Errorf("wrong limited priority: %s", p)
+	}
+	lgr.F(Info, "info")
+	lgr.F(Crit, "crit")
+	if s := file.String(); s != "id [C] crit\n" {
+		t.Errorf("wrong limited file: %q", s)
+	}
+	if s := alert.String(); s != "id [C] crit\n" {
+		t.Errorf("wrong limited alert: %q", s)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+)
+
+// RunIdLength is the number of characters in identifiers generated by
+// MakeRunIdLogger.
+const RunIdLength = 6
+
+// newRunId returns a random identifier of RunIdLength base32 characters.
+func newRunId() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return base32.StdEncoding.EncodeToString(b[:])[:RunIdLength]
+}
+
+// RunIdLogger wraps a Logger to prefix each message with a short random
+// identifier generated when the logger is constructed, rendered like
+// "(run=K3J9QZ) " at the start of the message text.  This distinguishes
+// the messages of separate runs or instances of a program that write to a
+// shared destination, without the noise of full host and process tagging.
+//
+// The identifier is RunIdLength characters of the standard base32
+// alphabet, and is stable for the lifetime of the logger.  All methods are
+// safe for concurrent use if the wrapped logger is.
+type RunIdLogger struct {
+	lgr Logger
+	id  string
+}
+
+// MakeRunIdLogger returns a RunIdLogger that forwards to lgr with a newly
+// generated run identifier.
+func MakeRunIdLogger(lgr Logger) *RunIdLogger {
+	return &RunIdLogger{
+		lgr: lgr,
+		id:  newRunId(),
+	}
+}
+
+// RunId returns the run identifier.
+func (v *RunIdLogger) RunId() string {
+	return v.id
+}
+
+// Priority per ImmutableLogger.
+func (v *RunIdLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.  Messages are formatted only if the underlying
+// logger would emit them.
+func (v *RunIdLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.lgr.F(pri, "(run=%s) %s", v.id, fmt.Sprintf(format, args...))
+}
+
+// Audit per Auditor.  The run identifier is added as with F.
+func (v *RunIdLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, "(run=%s) %s", v.id, fmt.Sprintf(format, args...))
+}
+
+// SetId per Logger.
+func (v *RunIdLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *RunIdLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
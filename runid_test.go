@@ -0,0 +1,33 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRunIdLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeRunIdLogger(newTestLogger(&sb))
+
+	id := lgr.RunId()
+	if !regexp.MustCompile(`^[A-Z2-7]{6}$`).MatchString(id) {
+		t.Errorf("bad run id: %q", id)
+	}
+	lgr.SetId("svc ")
+	lgr.F(Info, "one %d", 1)
+	lgr.SetPriority(Warning)
+	lgr.F(Info, "filtered")
+	lgr.F(Error, "two")
+	exp := "svc [I] (run=" + id + ") one 1\nsvc [E] (run=" + id + ") two\n"
+	if s := sb.String(); s != exp {
+		t.Errorf("wrong output: %q", s)
+	}
+
+	if id2 := MakeRunIdLogger(newTestLogger(&sb)).RunId(); id2 == id {
+		t.Errorf("run id reused: %s", id2)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultSDID is the SD-ID used by AppendStructuredData when none is
+// provided.  RFC 5424 requires SD-IDs that are not registered with IANA to
+// have the form name@number, where number is a Private Enterprise Number.
+// The default uses 32473, which RFC 5612 reserves for documentation;
+// applications that send structured data beyond a local collector should
+// use an SD-ID with their own enterprise number.
+const DefaultSDID = "fields@32473"
+
+// sdNameMax is the maximum length of an SD-NAME per RFC 5424.
+const sdNameMax = 32
+
+// AppendStructuredData appends to buf fields rendered as an RFC 5424
+// SD-ELEMENT with the given SD-ID, e.g. `[fields@32473 key="value"]`.  This
+// is the STRUCTURED-DATA part of an RFC 5424 syslog message, allowing
+// collectors to index the fields rather than parse them out of the message.
+//
+// Parameters appear in order of key.  Values are rendered with fmt.Sprint
+// and escaped as required by RFC 5424.  Characters that are not permitted in
+// an SD-NAME are replaced in keys by underscores, and keys are truncated to
+// 32 bytes.  An empty sdid is replaced by DefaultSDID.  If fields is empty
+// the NILVALUE "-" is appended.
+func AppendStructuredData(buf []byte, sdid string, fields Fields) []byte {
+	if len(fields) == 0 {
+		return append(buf, '-')
+	}
+	if sdid == "" {
+		sdid = DefaultSDID
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf = append(buf, '[')
+	buf = append(buf, sdid...)
+	for _, k := range keys {
+		buf = append(buf, ' ')
+		buf = appendSDName(buf, k)
+		buf = append(buf, '=', '"')
+		for _, c := range []byte(fmt.Sprint(fields[k])) {
+			if c == '"' || c == '\\' || c == ']' {
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, c)
+		}
+		buf = append(buf, '"')
+	}
+	return append(buf, ']')
+}
+
+// appendSDName appends name to buf as a valid RFC 5424 SD-NAME.
+func appendSDName(buf []byte, name string) []byte {
+	if name == "" {
+		return append(buf, '_')
+	}
+	if len(name) > sdNameMax {
+		name = name[:sdNameMax]
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
@@ -0,0 +1,30 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"testing"
+)
+
+func TestAppendStructuredData(t *testing.T) {
+	tests := []struct {
+		sdid   string
+		fields Fields
+		exp    string
+	}{
+		{"", nil, "-"},
+		{"", Fields{"a": 1}, `[fields@32473 a="1"]`},
+		{"app@12345", Fields{"b": "x y", "a": true}, `[app@12345 a="true" b="x y"]`},
+		{"", Fields{"q": `a"b\c]d`}, `[fields@32473 q="a\"b\\c\]d"]`},
+		{"", Fields{"k=v x]\"": 1}, `[fields@32473 k_v_x__="1"]`},
+		{"", Fields{"": 1}, `[fields@32473 _="1"]`},
+		{"", Fields{"abcdefghijklmnopqrstuvwxyz0123456789": 1},
+			`[fields@32473 abcdefghijklmnopqrstuvwxyz012345="1"]`},
+	}
+	for _, tc := range tests {
+		if s := string(AppendStructuredData(nil, tc.sdid, tc.fields)); s != tc.exp {
+			t.Errorf("%v: got %s, expected %s", tc.fields, s, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"strings"
+	"sync"
+)
+
+// lineRing is an io.Writer that retains the most recent lines written to it.
+// Each call to Write is treated as one line, which matches the way
+// LogLogger emits messages.
+type lineRing struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+}
+
+func newLineRing(capacity int) *lineRing {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &lineRing{
+		lines: make([]string, 0, capacity),
+	}
+}
+
+// Write adds data to the ring as a line, without any trailing newline.
+func (r *lineRing) Write(data []byte) (int, error) {
+	line := strings.TrimSuffix(string(data), "\n")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) < cap(r.lines) {
+		r.lines = append(r.lines, line)
+	} else {
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % len(r.lines)
+	}
+	return len(data), nil
+}
+
+// Lines returns a copy of the retained lines, oldest first.
+func (r *lineRing) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rv := make([]string, 0, len(r.lines))
+	rv = append(rv, r.lines[r.next:]...)
+	return append(rv, r.lines[:r.next]...)
+}
+
+// SliceLogger is a LogLogger that retains its most recent messages in
+// memory rather than writing them to a stream, for display in an embedded
+// user interface.  Messages are rendered exactly as LogLogger renders them,
+// including the date and time, identifier, and priority.
+//
+// All methods, including Lines, are safe for concurrent use.
+type SliceLogger struct {
+	*LogLogger
+	ring *lineRing
+}
+
+// MakeSliceLogger returns a SliceLogger that retains up to capacity
+// messages.  Values of capacity less than 1 are replaced by 1.  The initial
+// priority is Warning.
+func MakeSliceLogger(capacity int) *SliceLogger {
+	ring := newLineRing(capacity)
+	ll := LogLogMaker(nil).(*LogLogger)
+	ll.Instance().SetOutput(ring)
+	return &SliceLogger{
+		LogLogger: ll,
+		ring:      ring,
+	}
+}
+
+// Lines returns a copy of the retained messages, oldest first, without
+// trailing newlines.
+func (v *SliceLogger) Lines() []string {
+	return v.ring.Lines()
+}
+
+// TappedLogger wraps a Logger to also retain its most recent messages in
+// memory, so logs can be streamed live while recent history remains
+// available for inclusion in a crash dump.
+//
+// Every message emitted by the live logger is also rendered into a ring as
+// a SliceLogger would render it; messages filtered by the live logger are
+// not retained.  All methods, including Recent, are safe for concurrent use
+// if the live logger is.
+type TappedLogger struct {
+	lgr Logger
+	tap *SliceLogger
+}
+
+// MakeTappedLogger returns a TappedLogger that forwards to live and retains
+// up to capacity rendered messages.  Values of capacity less than 1 are
+// replaced by 1.
+func MakeTappedLogger(live Logger, capacity int) *TappedLogger {
+	tap := MakeSliceLogger(capacity)
+	tap.SetPriority(Debug)
+	return &TappedLogger{
+		lgr: live,
+		tap: tap,
+	}
+}
+
+// Recent returns a copy of the retained messages, oldest first, without
+// trailing newlines.
+func (v *TappedLogger) Recent() []string {
+	return v.tap.Lines()
+}
+
+// Tap returns the logger that renders retained messages, so its format can
+// be configured, e.g. with Instance().SetFlags.  Its priority should not be
+// changed.
+func (v *TappedLogger) Tap() *SliceLogger {
+	return v.tap
+}
+
+// Priority per ImmutableLogger.
+func (v *TappedLogger) Priority() Priority {
+	return v.lgr.Priority()
+}
+
+// F per ImmutableLogger.
+func (v *TappedLogger) F(pri Priority, format string, args ...interface{}) {
+	if !v.lgr.Priority().Enables(pri) {
+		return
+	}
+	v.lgr.F(pri, format, args...)
+	v.tap.F(pri, format, args...)
+}
+
+// Audit per Auditor.  Audit messages are passed to the live logger and
+// retained.
+func (v *TappedLogger) Audit(format string, args ...interface{}) {
+	Audit(v.lgr, format, args...)
+	Audit(v.tap, format, args...)
+}
+
+// SetId per Logger.  The identifier is applied to both the live logger and
+// the retained messages.
+func (v *TappedLogger) SetId(id string) Logger {
+	v.lgr.SetId(id)
+	v.tap.SetId(id)
+	return v
+}
+
+// SetPriority per Logger.
+func (v *TappedLogger) SetPriority(pri Priority) Logger {
+	v.lgr.SetPriority(pri)
+	return v
+}
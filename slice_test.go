@@ -0,0 +1,87 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSliceLogger(t *testing.T) {
+	lgr := MakeSliceLogger(3)
+	lgr.Instance().SetFlags(0)
+	lgr.SetId("ui ")
+
+	if l := lgr.Lines(); len(l) != 0 {
+		t.Errorf("not empty: %v", l)
+	}
+	lgr.F(Warning, "one")
+	lgr.F(Info, "filtered")
+	lgr.F(Error, "two")
+	if l := lgr.Lines(); !reflect.DeepEqual(l, []string{"ui [W] one", "ui [E] two"}) {
+		t.Errorf("wrong lines: %q", l)
+	}
+
+	lgr.F(Error, "three")
+	lgr.F(Error, "four")
+	lgr.F(Error, "five")
+	exp := []string{"ui [E] three", "ui [E] four", "ui [E] five"}
+	if l := lgr.Lines(); !reflect.DeepEqual(l, exp) {
+		t.Errorf("wrong wrapped lines: %q", l)
+	}
+	lgr.F(Error, "six")
+	if l := lgr.Lines(); !reflect.DeepEqual(l, append(exp[1:], "ui [E] six")) {
+		t.Errorf("wrong rewrapped lines: %q", l)
+	}
+
+	if c := cap(MakeSliceLogger(0).ring.lines); c != 1 {
+		t.Errorf("capacity not clamped: %d", c)
+	}
+}
+
+func TestSliceLoggerConcurrent(t *testing.T) {
+	lgr := MakeSliceLogger(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				lgr.F(Error, "%d.%d", i, j)
+				_ = lgr.Lines()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := len(lgr.Lines()); n != 10 {
+		t.Errorf("wrong line count: %d", n)
+	}
+}
+
+func TestTappedLogger(t *testing.T) {
+	var sb strings.Builder
+	lgr := MakeTappedLogger(newTestLogger(&sb), 2)
+	lgr.Tap().Instance().SetFlags(0)
+	lgr.SetId("app ")
+	lgr.SetPriority(Info)
+
+	lgr.F(Info, "one")
+	lgr.F(Debug, "filtered")
+	lgr.F(Warning, "two")
+	lgr.F(Error, "three")
+	if s := sb.String(); s != "app [I] one\napp [W] two\napp [E] three\n" {
+		t.Errorf("wrong live output: %q", s)
+	}
+	exp := []string{"app [W] two", "app [E] three"}
+	rv := lgr.Recent()
+	if !reflect.DeepEqual(rv, exp) {
+		t.Errorf("wrong recent: %q", rv)
+	}
+	rv[0] = "changed"
+	if rv = lgr.Recent(); !reflect.DeepEqual(rv, exp) {
+		t.Errorf("recent not a copy: %q", rv)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.21
+
+package logwrap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SlogIdAttribute is the key of the attribute that holds the identifier
+// assigned with SetId to loggers created by SlogDefaultLogMaker.  Records
+// from loggers with no identifier do not have the attribute.
+const SlogIdAttribute = "id"
+
+var slogLevelMap = map[Priority]slog.Level{
+	Emerg:   slog.LevelError + 8,
+	Crit:    slog.LevelError + 4,
+	Error:   slog.LevelError,
+	Warning: slog.LevelWarn,
+	Notice:  slog.LevelInfo + 2,
+	Info:    slog.LevelInfo,
+	Debug:   slog.LevelDebug,
+}
+
+// SlogLevel returns the slog level used for messages emitted at pri.
+// Notice, Crit, and Emerg use levels between and above the standard slog
+// levels so their precedence is retained.
+func SlogLevel(pri Priority) slog.Level {
+	return slogLevelMap[pri]
+}
+
+// SlogDefaultLogMaker returns a Logger that emits messages through the
+// logger returned by slog.Default at the time each message is logged,
+// rather than one captured when the Logger was created.  This keeps logwrap
+// output consistent with applications that replace the slog default at run
+// time.
+//
+// Resolving the default on each call costs an atomic load per message,
+// which is negligible compared with formatting.  Messages are dropped
+// without formatting if they are not enabled by either the Logger priority
+// or the current default slog handler.  Created loggers implement
+// FieldLogger, passing fields as slog attributes in order of key, with the
+// values of fields with keys in DefaultSensitiveKeys replaced by
+// MaskedValue.  The initial priority is Warning.
+func SlogDefaultLogMaker(interface{}) Logger {
+	return &slogDefaultLogger{
+		pri: Warning,
+	}
+}
+
+// slogDefaultLogger emits messages through slog.Default.  All methods are
+// safe for concurrent use.
+type slogDefaultLogger struct {
+	mu  sync.Mutex
+	id  string
+	pri Priority
+}
+
+// Priority per ImmutableLogger.
+func (v *slogDefaultLogger) Priority() Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pri
+}
+
+// F per ImmutableLogger.
+func (v *slogDefaultLogger) F(pri Priority, format string, args ...interface{}) {
+	v.emit(pri, nil, format, args...)
+}
+
+// FF per FieldLogger.
+func (v *slogDefaultLogger) FF(pri Priority, fields Fields, format string, args ...interface{}) {
+	v.emit(pri, fields, format, args...)
+}
+
+// emit implements F and FF.  It must be invoked directly by them so the
+// source location of the record identifies their caller.
+func (v *slogDefaultLogger) emit(pri Priority, fields Fields, format string, args ...interface{}) {
+	v.mu.Lock()
+	id, enabled := v.id, v.pri.Enables(pri)
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	fields = defaultSensitiveKeys.mask(fields)
+	ctx := context.Background()
+	h := slog.Default().Handler()
+	level := SlogLevel(pri)
+	if !h.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	if id != "" {
+		r.AddAttrs(slog.String(SlogIdAttribute, id))
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.AddAttrs(slog.Any(k, fields[k]))
+	}
+	_ = h.Handle(ctx, r)
+}
+
+// SetId per Logger.  The id is attached to records as an attribute rather
+// than being added to the message.
+func (v *slogDefaultLogger) SetId(id string) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.id = id
+	return v
+}
+
+// SetPriority per Logger.
+func (v *slogDefaultLogger) SetPriority(pri Priority) Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
@@ -0,0 +1,46 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.21
+
+package logwrap
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogDefaultLogMaker(t *testing.T) {
+	saved := slog.Default()
+	defer slog.SetDefault(saved)
+
+	lgr := SlogDefaultLogMaker(nil)
+	lgr.SetId("svc")
+
+	var sb1, sb2 strings.Builder
+	opts := &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(&sb1, opts)))
+	lgr.F(Error, "first %d", 1)
+	lgr.F(Info, "filtered")
+
+	// Replacing the default redirects existing loggers.
+	slog.SetDefault(slog.New(slog.NewTextHandler(&sb2, opts)))
+	lgr.SetPriority(Debug)
+	FF(lgr, Notice, Fields{"k": "v", "password": "hunter2"}, "second")
+
+	if s := sb1.String(); s != "level=ERROR msg=\"first 1\" id=svc\n" {
+		t.Errorf("wrong first output: %q", s)
+	}
+	if s := sb2.String(); s != "level=INFO+2 msg=second id=svc k=v password=***\n" {
+		t.Errorf("wrong second output: %q", s)
+	}
+}
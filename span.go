@@ -0,0 +1,56 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// newSpanId returns a random eight-character hexadecimal identifier.
+func newSpanId() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// StartSpan provides lightweight operation tracing.  It emits through lgr at
+// pri a message "span ID start name", and returns a function that emits
+// "span ID end name (duration)" where duration is the time since StartSpan
+// was invoked.  ID is a short random identifier shared by the two messages
+// so they can be correlated in a busy log.
+//
+// The returned function should be invoked once, typically with defer.
+func StartSpan(lgr ImmutableLogger, pri Priority, name string) func() {
+	id := newSpanId()
+	t0 := time.Now()
+	lgr.F(pri, "span %s start %s", id, name)
+	return func() {
+		lgr.F(pri, "span %s end %s (%s)", id, name, time.Since(t0))
+	}
+}
+
+// ExpectWithin supports latency budgets.  It returns a function that, when
+// invoked, emits through lgr a message with the time elapsed since
+// ExpectWithin was invoked.  If the elapsed time does not exceed d the
+// message "name completed in duration" is emitted at Info; otherwise
+// "name took duration, exceeding d" is emitted at Warning, so overruns
+// stand out without further configuration.  Nothing is formatted if lgr
+// would not emit the message.
+//
+// The returned function should be invoked once, typically with defer.
+func ExpectWithin(lgr ImmutableLogger, d time.Duration, name string) func() {
+	t0 := time.Now()
+	return func() {
+		dt := time.Since(t0)
+		if dt <= d {
+			if lgr.Priority().Enables(Info) {
+				lgr.F(Info, "%s completed in %s", name, dt)
+			}
+		} else if lgr.Priority().Enables(Warning) {
+			lgr.F(Warning, "%s took %s, exceeding %s", name, dt, d)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartSpan(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	end := StartSpan(lgr, Info, "load")
+	end()
+	re := regexp.MustCompile(`^\[I\] span ([0-9a-f]{8}) start load\n\[I\] span ([0-9a-f]{8}) end load \(\S+\)\n$`)
+	m := re.FindStringSubmatch(sb.String())
+	if m == nil || m[1] != m[2] {
+		t.Errorf("wrong span output: %q", sb.String())
+	}
+	sb.Reset()
+
+	StartSpan(lgr, Info, "other")()
+	if m2 := re.FindStringSubmatch(strings.ReplaceAll(sb.String(), "other", "load")); m2 == nil || m2[1] == m[1] {
+		t.Errorf("span id reused: %q", sb.String())
+	}
+}
+
+func TestExpectWithin(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+
+	ExpectWithin(lgr, time.Hour, "fast")()
+	if s := sb.String(); !regexp.MustCompile(`^\[I\] fast completed in \S+\n$`).MatchString(s) {
+		t.Errorf("wrong within output: %q", s)
+	}
+	sb.Reset()
+
+	done := ExpectWithin(lgr, time.Millisecond, "slow")
+	time.Sleep(2 * time.Millisecond)
+	done()
+	if s := sb.String(); !regexp.MustCompile(`^\[W\] slow took \S+, exceeding 1ms\n$`).MatchString(s) {
+		t.Errorf("wrong overrun output: %q", s)
+	}
+	sb.Reset()
+
+	lgr.SetPriority(Warning)
+	ExpectWithin(lgr, time.Hour, "quiet")()
+	if s := sb.String(); s != "" {
+		t.Errorf("filtered message emitted: %q", s)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// StripedWriter is an io.Writer that distributes successive writes
+// round-robin across a set of files, so that write-heavy logging can use the
+// bandwidth of several devices.  Each write goes to exactly one file, and
+// writes to each file are serialized independently.
+//
+// Ordering is preserved within each file but not across files; merge the
+// files by timestamp to reconstruct the full log.
+type StripedWriter struct {
+	files []*os.File
+	mus   []sync.Mutex
+	next  uint64
+}
+
+// OpenStripedWriter opens or creates each of paths for appending, and
+// returns a StripedWriter that distributes writes across them.  If any file
+// cannot be opened the ones already opened are closed and the error is
+// returned.
+func OpenStripedWriter(paths []string) (*StripedWriter, error) {
+	if len(paths) == 0 {
+		return nil, os.ErrInvalid
+	}
+	w := &StripedWriter{
+		files: make([]*os.File, 0, len(paths)),
+		mus:   make([]sync.Mutex, len(paths)),
+	}
+	for _, p := range paths {
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+		w.files = append(w.files, f)
+	}
+	return w, nil
+}
+
+// Write writes data to the next file in sequence.
+func (w *StripedWriter) Write(data []byte) (int, error) {
+	i := int((atomic.AddUint64(&w.next, 1) - 1) % uint64(len(w.files)))
+	w.mus[i].Lock()
+	defer w.mus[i].Unlock()
+	return w.files[i].Write(data)
+}
+
+// Close closes all the files, returning the first error encountered.
+func (w *StripedWriter) Close() (err error) {
+	for i, f := range w.files {
+		w.mus[i].Lock()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		w.mus[i].Unlock()
+	}
+	return
+}
+
+// StripedFileLogMaker opens a StripedWriter on paths and returns a LogMaker
+// that creates LogLogger instances that write to it, along with the writer
+// so the application can close it on shutdown.  Since LogLogger writes each
+// message with a single write, each message is written entirely to one
+// file.
+//
+// Created loggers include microseconds in their timestamps, to support
+// merging the files.  The initial priority is Warning.
+func StripedFileLogMaker(paths []string) (LogMaker, *StripedWriter, error) {
+	w, err := OpenStripedWriter(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(owner interface{}) Logger {
+		lgr := LogLogMaker(owner)
+		inst := lgr.(*LogLogger).Instance()
+		inst.SetFlags(log.LstdFlags | log.Lmicroseconds)
+		inst.SetOutput(w)
+		return lgr
+	}, w, nil
+}
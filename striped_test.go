@@ -0,0 +1,49 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripedFileLogMaker(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{
+		filepath.Join(dir, "a.log"),
+		filepath.Join(dir, "b.log"),
+	}
+
+	if _, _, err := StripedFileLogMaker(nil); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("empty paths accepted: %v", err)
+	}
+	if _, _, err := StripedFileLogMaker(append(paths, filepath.Join(dir, "missing", "c.log"))); err == nil {
+		t.Errorf("bad path accepted")
+	}
+
+	mk, w, err := StripedFileLogMaker(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr := mk(nil)
+	for _, m := range []string{"one", "two", "three"} {
+		lgr.F(Error, "%s", m)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := os.ReadFile(paths[0])
+	b, _ := os.ReadFile(paths[1])
+	as, bs := string(a), string(b)
+	if strings.Count(as, "\n") != 2 || !strings.Contains(as, "[E] one\n") || !strings.HasSuffix(as, "[E] three\n") {
+		t.Errorf("wrong first file: %q", as)
+	}
+	if strings.Count(bs, "\n") != 1 || !strings.HasSuffix(bs, "[E] two\n") {
+		t.Errorf("wrong second file: %q", bs)
+	}
+}
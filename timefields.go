@@ -0,0 +1,145 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// DurationEncoding selects how structured sinks encode DurationValue
+// fields.
+type DurationEncoding int32
+
+const (
+	// DurationSeconds encodes durations as a number of seconds, with a
+	// fractional part where required.  This is the default.
+	DurationSeconds DurationEncoding = iota
+	// DurationISO8601 encodes durations as ISO 8601 duration strings
+	// such as PT1M30.5S.
+	DurationISO8601
+)
+
+// durationEncoding holds the DurationEncoding used by structured sinks.
+// Access it atomically.
+var durationEncoding int32
+
+// SetDurationEncoding selects how structured sinks encode DurationValue
+// fields emitted after the call.  This allows an application to match the
+// expectations of its log pipeline in one place.  This function is safe for
+// concurrent use.
+func SetDurationEncoding(enc DurationEncoding) {
+	atomic.StoreInt32(&durationEncoding, int32(enc))
+}
+
+// structuredValue is implemented by field values that have a distinct
+// representation for structured sinks.
+type structuredValue interface {
+	// structured returns the value as a string or number.
+	structured() interface{}
+}
+
+// DurationValue is a field value holding a duration.  Text sinks render it
+// in the form produced by time.Duration, e.g. 1m30.5s, while structured
+// sinks encode it as selected by SetDurationEncoding.
+type DurationValue time.Duration
+
+// Duration returns a Fields holding d as a DurationValue under key.  The
+// result can be passed directly to FF, or combined with other fields using
+// MergeFields.
+func Duration(key string, d time.Duration) Fields {
+	return Fields{key: DurationValue(d)}
+}
+
+// String renders the duration in human-readable form.
+func (v DurationValue) String() string {
+	return time.Duration(v).String()
+}
+
+// MarshalText renders the duration in human-readable form.
+func (v DurationValue) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// MarshalJSON encodes the duration as selected by SetDurationEncoding.
+func (v DurationValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.structured())
+}
+
+func (v DurationValue) structured() interface{} {
+	if DurationEncoding(atomic.LoadInt32(&durationEncoding)) == DurationISO8601 {
+		return isoDuration(time.Duration(v))
+	}
+	return time.Duration(v).Seconds()
+}
+
+// isoDuration renders d as an ISO 8601 duration using hours, minutes, and
+// seconds, with a leading minus sign if d is negative.
+func isoDuration(d time.Duration) string {
+	buf := make([]byte, 0, 24)
+	u := uint64(d)
+	if d < 0 {
+		buf = append(buf, '-')
+		u = -u
+	}
+	buf = append(buf, "PT"...)
+	h := u / uint64(time.Hour)
+	u -= h * uint64(time.Hour)
+	m := u / uint64(time.Minute)
+	u -= m * uint64(time.Minute)
+	if h > 0 {
+		buf = strconv.AppendUint(buf, h, 10)
+		buf = append(buf, 'H')
+	}
+	if m > 0 {
+		buf = strconv.AppendUint(buf, m, 10)
+		buf = append(buf, 'M')
+	}
+	if u > 0 || h+m == 0 {
+		buf = strconv.AppendUint(buf, u/uint64(time.Second), 10)
+		if ns := u % uint64(time.Second); ns > 0 {
+			frac := strconv.FormatUint(ns+uint64(time.Second), 10)[1:]
+			for frac[len(frac)-1] == '0' {
+				frac = frac[:len(frac)-1]
+			}
+			buf = append(buf, '.')
+			buf = append(buf, frac...)
+		}
+		buf = append(buf, 'S')
+	}
+	return string(buf)
+}
+
+// TimeValue is a field value holding a point in time.  Text sinks render it
+// in RFC 3339 format in its own location, while structured sinks encode it
+// as an RFC 3339 string in UTC.
+type TimeValue time.Time
+
+// Time returns a Fields holding t as a TimeValue under key.  The result can
+// be passed directly to FF, or combined with other fields using
+// MergeFields.
+func Time(key string, t time.Time) Fields {
+	return Fields{key: TimeValue(t)}
+}
+
+// String renders the time in RFC 3339 format.
+func (v TimeValue) String() string {
+	return time.Time(v).Format(time.RFC3339Nano)
+}
+
+// MarshalText renders the time in RFC 3339 format.
+func (v TimeValue) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// MarshalJSON encodes the time as an RFC 3339 string in UTC.
+func (v TimeValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.structured())
+}
+
+func (v TimeValue) structured() interface{} {
+	return time.Time(v).UTC().Format(time.RFC3339Nano)
+}
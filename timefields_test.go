@@ -0,0 +1,75 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package logwrap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFieldsText(t *testing.T) {
+	var sb strings.Builder
+	lgr := newTestLogger(&sb)
+	t0 := time.Date(2022, 6, 25, 10, 30, 0, 500000000, time.FixedZone("X", 3600))
+
+	fields, _ := MergeFields(Duration("elapsed", 90500*time.Millisecond), Time("start", t0))
+	FF(lgr, Info, fields, "done")
+	if s := sb.String(); s != "[I] done elapsed=1m30.5s start=2022-06-25T10:30:00.5+01:00\n" {
+		t.Errorf("wrong text: %q", s)
+	}
+}
+
+func TestTimeFieldsStructured(t *testing.T) {
+	defer SetDurationEncoding(DurationSeconds)
+	t0 := time.Date(2022, 6, 25, 10, 30, 0, 500000000, time.FixedZone("X", 3600))
+	fields, _ := MergeFields(Duration("elapsed", 90500*time.Millisecond), Time("start", t0))
+
+	decode := func() map[string]interface{} {
+		t.Helper()
+		var sb strings.Builder
+		FF(ECSLogMaker(&sb)(nil), Warning, fields, "done")
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(sb.String()), &obj); err != nil {
+			t.Fatalf("bad json: %v: %q", err, sb.String())
+		}
+		return obj
+	}
+
+	obj := decode()
+	if v, ok := obj["elapsed"].(float64); !ok || v != 90.5 {
+		t.Errorf("wrong seconds: %#v", obj["elapsed"])
+	}
+	if v := obj["start"]; v != "2022-06-25T09:30:00.5Z" {
+		t.Errorf("wrong time: %#v", v)
+	}
+	if v := gelfValue(fields["elapsed"]); v != 90.5 {
+		t.Errorf("wrong GELF seconds: %#v", v)
+	}
+
+	SetDurationEncoding(DurationISO8601)
+	obj = decode()
+	if v := obj["elapsed"]; v != "PT1M30.5S" {
+		t.Errorf("wrong ISO duration: %#v", v)
+	}
+	if v := gelfValue(fields["start"]); v != "2022-06-25T09:30:00.5Z" {
+		t.Errorf("wrong GELF time: %#v", v)
+	}
+}
+
+func TestISODuration(t *testing.T) {
+	for d, exp := range map[time.Duration]string{
+		0:                                     "PT0S",
+		time.Nanosecond:                       "PT0.000000001S",
+		1500 * time.Millisecond:               "PT1.5S",
+		time.Minute:                           "PT1M",
+		26*time.Hour + 3*time.Second:          "PT26H3S",
+		-(2*time.Hour + 250*time.Millisecond): "-PT2H0.25S",
+	} {
+		if s := isoDuration(d); s != exp {
+			t.Errorf("%s: got %s, expected %s", d, s, exp)
+		}
+	}
+}
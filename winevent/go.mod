@@ -0,0 +1,10 @@
+module github.com/pabigot/logwrap/winevent
+
+go 1.18
+
+require (
+	github.com/pabigot/logwrap v0.3.0
+	golang.org/x/sys v0.20.0
+)
+
+replace github.com/pabigot/logwrap => ../
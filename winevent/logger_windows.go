@@ -0,0 +1,97 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package winevent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	lw "github.com/pabigot/logwrap"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// Logger emits logwrap messages to a Windows Event Log source.  All methods
+// are safe for concurrent use.
+type Logger struct {
+	el  *eventlog.Log
+	mu  sync.Mutex
+	id  string
+	pri lw.Priority
+}
+
+// WindowsEventLogMaker registers source as an event source if it is not
+// already registered, and returns a LogMaker that creates loggers emitting
+// messages to it along with the event log handle, which should be closed
+// when the loggers are no longer used.  Registration uses the EventCreate
+// message file and normally requires administrative privileges; it is
+// skipped without error if the source already exists.
+//
+// The event type is selected by TypeOf and the event identifier by
+// EventId.  The event log API provided by golang.org/x/sys does not expose
+// event categories, so an identifier assigned with SetId is added to the
+// message as by LogLogger.  Created loggers have priority Warning.
+func WindowsEventLogMaker(source string) (lw.LogMaker, *eventlog.Log, error) {
+	err := eventlog.InstallAsEventCreate(source,
+		eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && !strings.HasSuffix(err.Error(), "registry key already exists") {
+		return nil, nil, err
+	}
+	el, err := eventlog.Open(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	mk := func(interface{}) lw.Logger {
+		return &Logger{
+			el:  el,
+			pri: lw.Warning,
+		}
+	}
+	return mk, el, nil
+}
+
+// Priority per logwrap.ImmutableLogger.
+func (v *Logger) Priority() lw.Priority {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pri
+}
+
+// F per logwrap.ImmutableLogger.  Failures to write the event are ignored.
+func (v *Logger) F(pri lw.Priority, format string, args ...interface{}) {
+	v.mu.Lock()
+	id, enabled := v.id, v.pri.Enables(pri)
+	v.mu.Unlock()
+	if !enabled {
+		return
+	}
+	msg := id + fmt.Sprintf(format, args...)
+	eid := EventId(pri)
+	switch TypeOf(pri) {
+	case Error:
+		_ = v.el.Error(eid, msg)
+	case Warning:
+		_ = v.el.Warning(eid, msg)
+	default:
+		_ = v.el.Info(eid, msg)
+	}
+}
+
+// SetId per logwrap.Logger.
+func (v *Logger) SetId(id string) lw.Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.id = id
+	return v
+}
+
+// SetPriority per logwrap.Logger.
+func (v *Logger) SetPriority(pri lw.Priority) lw.Logger {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pri = pri
+	return v
+}
@@ -0,0 +1,55 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package winevent provides a logwrap.Logger that emits messages to the
+// Windows Event Log.  It is a separate module so that applications that do
+// not target Windows do not depend on it.  The LogMaker is only available
+// when building for Windows; the priority mapping is available everywhere
+// so it can be inspected and tested on any platform.
+package winevent
+
+import (
+	lw "github.com/pabigot/logwrap"
+)
+
+// EventType identifies the three Windows event types used for messages.
+type EventType int
+
+const (
+	// Information is used for Notice, Info, and Debug messages.
+	Information EventType = iota
+	// Warning is used for Warning messages.
+	Warning
+	// Error is used for Emerg, Crit, and Error messages.
+	Error
+)
+
+// String returns the Windows name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case Error:
+		return "Error"
+	case Warning:
+		return "Warning"
+	}
+	return "Information"
+}
+
+// TypeOf returns the event type used for messages emitted at pri.
+func TypeOf(pri lw.Priority) EventType {
+	switch {
+	case pri <= lw.Error:
+		return Error
+	case pri == lw.Warning:
+		return Warning
+	}
+	return Information
+}
+
+// EventId returns the event identifier used for messages emitted at pri.
+// This is one more than the syslog level of the priority, which keeps
+// the value within the range supported by the EventCreate message file
+// while allowing event viewers to filter on the original priority.
+func EventId(pri lw.Priority) uint32 {
+	return uint32(pri.Level() + 1)
+}
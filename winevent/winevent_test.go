@@ -0,0 +1,34 @@
+// Copyright 2021-2022 Peter Bigot Consulting, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package winevent
+
+import (
+	"testing"
+
+	lw "github.com/pabigot/logwrap"
+)
+
+func TestTypeOf(t *testing.T) {
+	tests := []struct {
+		pri lw.Priority
+		et  EventType
+		eid uint32
+	}{
+		{lw.Emerg, Error, 1},
+		{lw.Crit, Error, 3},
+		{lw.Error, Error, 4},
+		{lw.Warning, Warning, 5},
+		{lw.Notice, Information, 6},
+		{lw.Info, Information, 7},
+		{lw.Debug, Information, 8},
+	}
+	for _, tc := range tests {
+		if et := TypeOf(tc.pri); et != tc.et {
+			t.Errorf("%s: type %s, expected %s", tc.pri, et, tc.et)
+		}
+		if eid := EventId(tc.pri); eid != tc.eid {
+			t.Errorf("%s: id %d, expected %d", tc.pri, eid, tc.eid)
+		}
+	}
+}